@@ -0,0 +1,158 @@
+// Program algex is a calculator for the algex packages.
+//
+// Given file arguments, algex runs each file as an interp script and
+// exits with a non-zero status if any statement fails, including a
+// failed assertion. The -q flag suppresses the display of results, so
+// only failures are reported.
+//
+// Otherwise, algex runs interactively. Each line read is executed as
+// an interp statement, and the result is displayed. In addition to the
+// interp language, "history" lists the lines entered so far, "!n"
+// re-executes line n of the history, "!!" re-executes the previous line
+// and "quit" exits. Interrupting algex while it evaluates a statement
+// abandons that statement.
+//
+// The -max_terms, -max_degree and -max_coeff_bits flags bound the size
+// of the expressions computed, and -timeout bounds the time taken by
+// each interactive statement or each script.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"algex/interp"
+	"algex/terms"
+)
+
+var (
+	quiet        = flag.Bool("q", false, "only report failures when running scripts")
+	maxTerms     = flag.Int("max_terms", 0, "maximum number of terms in an expression, 0 for no limit")
+	maxDegree    = flag.Int("max_degree", 0, "maximum power of a symbol, 0 for no limit")
+	maxCoeffBits = flag.Int("max_coeff_bits", 0, "maximum bit length of a coefficient, 0 for no limit")
+	timeout      = flag.Duration("timeout", 0, "maximum time for a statement or script, 0 for no limit")
+)
+
+// limited returns a context applying the limits set by flags, and a
+// function to release it.
+func limited() (context.Context, context.CancelFunc) {
+	ctx := terms.WithLimits(context.Background(), terms.Limits{
+		MaxTerms:     *maxTerms,
+		MaxDegree:    *maxDegree,
+		MaxCoeffBits: *maxCoeffBits,
+	})
+	if *timeout > 0 {
+		return context.WithTimeout(ctx, *timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// exec executes a line with the limits set by flags. An interrupt
+// cancels the execution.
+func exec(in *interp.Interp, line string) (string, error) {
+	ctx, cancel := limited()
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return in.ExecCtx(ctx, line)
+}
+
+// run runs each of the named scripts with a fresh interpreter. It
+// reports whether all of them succeeded.
+func run(names []string) bool {
+	var w io.Writer = os.Stdout
+	if *quiet {
+		w = io.Discard
+	}
+	ok := true
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+			continue
+		}
+		ctx, cancel := limited()
+		err = interp.New().RunCtx(ctx, name, f, w)
+		cancel()
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 0 {
+		if !run(flag.Args()) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	in := interp.New()
+	var history []string
+
+	prompt := ""
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		prompt = "algex> "
+	}
+	sc := bufio.NewScanner(os.Stdin)
+	for fmt.Print(prompt); sc.Scan(); fmt.Print(prompt) {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "quit" || line == "exit":
+			return
+		case line == "history":
+			for i, h := range history {
+				fmt.Printf("%4d  %s\n", i+1, h)
+			}
+			continue
+		case line == "!!":
+			if len(history) == 0 {
+				fmt.Println("error: no history")
+				continue
+			}
+			line = history[len(history)-1]
+			fmt.Println(line)
+		case strings.HasPrefix(line, "!"):
+			n, err := strconv.Atoi(line[1:])
+			if err != nil || n < 1 || n > len(history) {
+				fmt.Printf("error: no history entry %q\n", line[1:])
+				continue
+			}
+			line = history[n-1]
+			fmt.Println(line)
+		}
+		if line != "" {
+			history = append(history, line)
+		}
+		out, err := exec(in, line)
+		if err != nil {
+			fmt.Println("error:", err)
+		} else if out != "" {
+			fmt.Println(out)
+		}
+	}
+	if prompt != "" {
+		fmt.Println()
+	}
+}
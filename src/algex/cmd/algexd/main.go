@@ -0,0 +1,37 @@
+// Program algexd serves the algex operations of package server as a
+// local HTTP/JSON API.
+//
+// For example:
+//
+//	algexd --addr=localhost:8080 &
+//	curl -d '{"op": "mul", "args": ["a+b", "a-b"]}' localhost:8080/eval
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"algex/server"
+)
+
+var (
+	addr         = flag.String("addr", "localhost:8080", "address to listen on")
+	maxTerms     = flag.Int("max_terms", 10000, "maximum number of terms in an argument or result, 0 for no limit")
+	maxDegree    = flag.Int("max_degree", 0, "maximum power of a symbol in a result, 0 for no limit")
+	maxCoeffBits = flag.Int("max_coeff_bits", 0, "maximum bit length of a coefficient in a result, 0 for no limit")
+	timeout      = flag.Duration("timeout", 10*time.Second, "maximum time to compute a result, 0 for no limit")
+)
+
+func main() {
+	flag.Parse()
+	s := &server.Server{
+		MaxTerms:     *maxTerms,
+		MaxDegree:    *maxDegree,
+		MaxCoeffBits: *maxCoeffBits,
+		Timeout:      *timeout,
+	}
+	log.Printf("serving algex on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
@@ -26,6 +26,18 @@ func (v Value) Num() *big.Rat {
 	return v.num
 }
 
+// Sym returns the symbol name of a non-numeric factor, or "" for a
+// number.
+func (v Value) Sym() string {
+	return v.sym
+}
+
+// Pow returns the power to which the symbol of a non-numeric factor is
+// raised. Numbers have a power of zero.
+func (v Value) Pow() int {
+	return v.pow
+}
+
 // String displays a single factor.
 func (v Value) String() string {
 	if v.num != nil {
@@ -0,0 +1,265 @@
+package interp
+
+import (
+	"fmt"
+
+	"algex/matrix"
+	"algex/rotation"
+	"algex/syntax"
+	"algex/terms"
+)
+
+// scope returns the variables and functions of the language, which
+// extend the algex syntax.
+func (in *Interp) scope() *syntax.Scope {
+	return &syntax.Scope{
+		Var: func(name string) (syntax.Value, bool) {
+			v, ok := in.vars[name]
+			return v.export(), ok
+		},
+		Funcs: map[string]func(a *syntax.Args) (syntax.Value, error){
+			"expand":    expandFunc,
+			"subst":     substFunc,
+			"diff":      diffFunc,
+			"collect":   collectFunc,
+			"RX":        rotationFunc(rotation.RX),
+			"RY":        rotationFunc(rotation.RY),
+			"RZ":        rotationFunc(rotation.RZ),
+			"identity":  identityFunc,
+			"transpose": transposeFunc,
+			"det":       detFunc,
+		},
+	}
+}
+
+// export converts v for evaluation by the syntax package. The symbol
+// an expression is collected by is held in its note.
+func (v Value) export() syntax.Value {
+	return syntax.Value{Exp: v.Exp, Matrix: v.Matrix, Note: v.by}
+}
+
+// value converts the result of an evaluation into a Value.
+func value(v syntax.Value) Value {
+	return Value{Exp: v.Exp, Matrix: v.Matrix, by: v.Note}
+}
+
+// expandFunc implements expand(e).
+func expandFunc(a *syntax.Args) (syntax.Value, error) {
+	v, err := a.Value()
+	v.Note = ""
+	return v, err
+}
+
+// substFunc implements subst(e, p, r).
+func substFunc(a *syntax.Args) (syntax.Value, error) {
+	v, err := a.Value()
+	if err != nil {
+		return v, err
+	}
+	b, err := a.Literal()
+	if err != nil {
+		return b, err
+	}
+	if b.Exp == nil || len(b.Exp.Terms()) != 1 {
+		return syntax.Value{}, fmt.Errorf("pattern must be a single term")
+	}
+	pat := b.Exp.Terms()
+	c, err := a.Value()
+	if err != nil {
+		return c, err
+	}
+	if c.Exp == nil {
+		return syntax.Value{}, fmt.Errorf("replacement must be an expression")
+	}
+	if v.Matrix != nil {
+		m, err := v.Matrix.SubstituteCtx(a.Context(), pat[0], c.Exp)
+		return syntax.Value{Matrix: m}, err
+	}
+	e, err := terms.SubstituteCtx(a.Context(), v.Exp, pat[0], c.Exp)
+	return syntax.Value{Exp: e, Note: v.Note}, err
+}
+
+// collectFunc implements collect(e, x).
+func collectFunc(a *syntax.Args) (syntax.Value, error) {
+	v, err := a.Value()
+	if err != nil {
+		return v, err
+	}
+	sym, err := a.Symbol()
+	if err != nil {
+		return v, err
+	}
+	if v.Exp == nil {
+		return syntax.Value{}, fmt.Errorf("unable to collect a matrix")
+	}
+	v.Note = sym
+	return v, nil
+}
+
+// diffFunc implements diff(e, x[, n]).
+func diffFunc(a *syntax.Args) (syntax.Value, error) {
+	v, err := a.Value()
+	if err != nil {
+		return v, err
+	}
+	sym, err := a.Symbol()
+	if err != nil {
+		return v, err
+	}
+	n := 1
+	if a.More() {
+		w, err := a.Value()
+		if err != nil {
+			return w, err
+		}
+		if n, err = integer(w); err != nil || n < 0 {
+			return syntax.Value{}, fmt.Errorf("order must be a non-negative integer")
+		}
+	}
+	// A polynomial vanishes once differentiated beyond its degree.
+	if lo, hi := degrees(v, sym); lo >= 0 && n > hi {
+		n = hi + 1
+	}
+	for ; n > 0; n-- {
+		if err := a.Context().Err(); err != nil {
+			return syntax.Value{}, err
+		}
+		v = diff(v, sym)
+	}
+	return v, nil
+}
+
+// degrees returns the lowest and highest powers of sym in v.
+func degrees(v syntax.Value, sym string) (lo, hi int) {
+	es := []*terms.Exp{v.Exp}
+	if v.Matrix != nil {
+		es = nil
+		rows, cols := v.Matrix.Dims()
+		for r := 0; r < rows; r++ {
+			for c := 0; c < cols; c++ {
+				e, _ := v.Matrix.El(r, c)
+				es = append(es, e)
+			}
+		}
+	}
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		for p := range e.Collect(sym) {
+			if p < lo {
+				lo = p
+			}
+			if p > hi {
+				hi = p
+			}
+		}
+	}
+	return lo, hi
+}
+
+// rotationFunc returns a function implementing RX(t), RY(t) or RZ(t)
+// with the rotation r. The angle is a symbol name optionally prefixed
+// by a number, so 2t names the angle of the symbols c2t and s2t.
+func rotationFunc(r func(string) *matrix.Matrix) func(a *syntax.Args) (syntax.Value, error) {
+	return func(a *syntax.Args) (syntax.Value, error) {
+		theta, err := a.Word()
+		if err != nil {
+			return syntax.Value{}, err
+		}
+		return syntax.Value{Matrix: r(theta)}, nil
+	}
+}
+
+// identityFunc implements identity(n).
+func identityFunc(a *syntax.Args) (syntax.Value, error) {
+	v, err := a.Value()
+	if err != nil {
+		return v, err
+	}
+	n, err := integer(v)
+	if err != nil {
+		return syntax.Value{}, fmt.Errorf("dimension: %v", err)
+	}
+	m, err := matrix.Identity(n)
+	return syntax.Value{Matrix: m}, err
+}
+
+// matrixArg parses an argument that must be a matrix.
+func matrixArg(a *syntax.Args) (*matrix.Matrix, error) {
+	v, err := a.Value()
+	if err != nil {
+		return nil, err
+	}
+	if v.Matrix == nil {
+		return nil, fmt.Errorf("matrix required")
+	}
+	return v.Matrix, nil
+}
+
+// transposeFunc implements transpose(m).
+func transposeFunc(a *syntax.Args) (syntax.Value, error) {
+	m, err := matrixArg(a)
+	if err != nil {
+		return syntax.Value{}, err
+	}
+	return syntax.Value{Matrix: m.Transpose()}, nil
+}
+
+// detFunc implements det(m).
+func detFunc(a *syntax.Args) (syntax.Value, error) {
+	m, err := matrixArg(a)
+	if err != nil {
+		return syntax.Value{}, err
+	}
+	d, err := m.Det()
+	return syntax.Value{Exp: d}, err
+}
+
+// equal indicates that two values are equal.
+func equal(a, b Value) bool {
+	if a.Exp != nil && b.Exp != nil {
+		return terms.Sub(a.Exp, b.Exp).String() == "0"
+	}
+	if a.Matrix == nil || b.Matrix == nil {
+		return false
+	}
+	return matrix.Equal(a.Matrix, b.Matrix)
+}
+
+// integer converts a value to an int.
+func integer(v syntax.Value) (int, error) {
+	if v.Exp != nil {
+		if n, ok := v.Exp.AsNumber(); ok && n.IsInt() && n.Num().IsInt64() {
+			if i := n.Num().Int64(); int64(int(i)) == i {
+				return int(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("integer required")
+}
+
+// elements applies f to every element of a matrix, treating nil
+// elements as zero.
+func elements(m *matrix.Matrix, f func(e *terms.Exp) *terms.Exp) *matrix.Matrix {
+	rows, cols := m.Dims()
+	n, _ := matrix.NewMatrix(rows, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			if e == nil {
+				e = terms.NewExp()
+			}
+			n.Set(r, c, f(e))
+		}
+	}
+	return n
+}
+
+// diff differentiates a value with respect to sym.
+func diff(v syntax.Value, sym string) syntax.Value {
+	if v.Matrix != nil {
+		return syntax.Value{Matrix: elements(v.Matrix, func(e *terms.Exp) *terms.Exp { return terms.Diff(e, sym) })}
+	}
+	return syntax.Value{Exp: terms.Diff(v.Exp, sym), Note: v.Note}
+}
@@ -0,0 +1,327 @@
+// Package interp evaluates statements in a small algebra language so
+// the algex packages can be used without writing Go.
+//
+// A statement is an expression, an assignment of the form
+// "name = expression" or a command. Expressions are built from numbers,
+// symbols, variables, the operators + - * / and ^ (integer powers),
+// matrix literals such as [[a, b], [c, d]] and these functions:
+//
+//	expand(e)          the expanded form of e
+//	subst(e, p, r)     e with each occurrence of the term p replaced by r
+//	diff(e, x[, n])    the n-th derivative of e with respect to x
+//	collect(e, x)      e displayed grouped by powers of x
+//	RX(t), RY(t), RZ(t)  rotation matrices for the angle t
+//	identity(n)        the n x n identity matrix
+//	transpose(m)       the transpose of the matrix m
+//	det(m)             the determinant of the square matrix m
+//
+// The commands are "vars", which lists the defined variables, "help"
+// and "assert a == b", which fails unless a and b are equal.
+//
+// Run executes a script of statements, one per line, so derivations
+// can be checked in and re-run.
+//
+// ExecCtx and RunCtx evaluate under a context, so evaluation can be
+// canceled and bounded with terms.WithLimits.
+package interp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"algex/matrix"
+	"algex/syntax"
+	"algex/terms"
+)
+
+// Value is the result of evaluating an expression. Exactly one of Exp
+// and Matrix is set.
+type Value struct {
+	Exp    *terms.Exp
+	Matrix *matrix.Matrix
+
+	// by, when set, is the symbol Exp is displayed collected by.
+	by string
+}
+
+// String displays a value. Matrices are displayed one row per line.
+func (v Value) String() string {
+	if v.Matrix != nil {
+		return pretty(v.Matrix)
+	}
+	if v.by != "" {
+		return collected(v.Exp, v.by)
+	}
+	return v.Exp.String()
+}
+
+// pretty displays a matrix with aligned columns.
+func pretty(m *matrix.Matrix) string {
+	rows, cols := m.Dims()
+	cs := make([][]string, rows)
+	w := make([]int, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			s := e.String()
+			cs[r] = append(cs[r], s)
+			if len(s) > w[c] {
+				w[c] = len(s)
+			}
+		}
+	}
+	var lines []string
+	for r := 0; r < rows; r++ {
+		var xs []string
+		for c, s := range cs[r] {
+			xs = append(xs, fmt.Sprintf("%-*s", w[c], s))
+		}
+		lines = append(lines, "[ "+strings.Join(xs, "  ")+" ]")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collected displays e as a sum of coefficients of descending powers
+// of sym.
+func collected(e *terms.Exp, sym string) string {
+	cs := e.Collect(sym)
+	var ps []int
+	for p := range cs {
+		ps = append(ps, p)
+	}
+	if len(ps) == 0 {
+		return "0"
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ps)))
+	var b strings.Builder
+	for i, p := range ps {
+		c := cs[p]
+		s := c.String()
+		x := sym
+		if p != 1 {
+			x = fmt.Sprintf("%s^%d", sym, p)
+		}
+		switch {
+		case p == 0:
+		case s == "1":
+			s = x
+		case s == "-1":
+			s = "-" + x
+		case len(c.Terms()) == 1:
+			s = s + "*" + x
+		default:
+			s = "(" + s + ")*" + x
+		}
+		if i != 0 {
+			if strings.HasPrefix(s, "-") {
+				s = " - " + s[1:]
+			} else {
+				s = " + " + s
+			}
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// Interp holds the variables of an interpreter session.
+type Interp struct {
+	vars map[string]Value
+}
+
+// New creates an interpreter with no variables defined.
+func New() *Interp {
+	return &Interp{
+		vars: make(map[string]Value),
+	}
+}
+
+// Var returns the value of a variable.
+func (in *Interp) Var(name string) (Value, bool) {
+	v, ok := in.vars[name]
+	return v, ok
+}
+
+// help describes the language.
+const help = `statements:
+  name = expression   assign a variable
+  expression          display an expression or matrix
+  vars                list the variables
+  assert a == b       fail unless a equals b
+functions:
+  expand(e) subst(e, pattern, replacement) diff(e, x[, n]) collect(e, x)
+  RX(t) RY(t) RZ(t) identity(n) transpose(m) det(m)
+matrices are written [[a, b], [c, d]]`
+
+// Exec executes a single statement and returns the text to display.
+// Blank lines and lines starting with '#' are ignored.
+func (in *Interp) Exec(line string) (string, error) {
+	return in.ExecCtx(context.Background(), line)
+}
+
+// ExecCtx executes a single statement like Exec. Evaluation fails if
+// ctx is done or the terms.Limits of ctx are exceeded.
+func (in *Interp) ExecCtx(ctx context.Context, line string) (string, error) {
+	line = strings.TrimSpace(line)
+	switch line {
+	case "":
+		return "", nil
+	case "help":
+		return help, nil
+	case "vars":
+		var names []string
+		for n := range in.vars {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		var xs []string
+		for _, n := range names {
+			v := " " + in.vars[n].String()
+			if in.vars[n].Matrix != nil {
+				v = "\n" + v[1:]
+			}
+			xs = append(xs, n+" ="+v)
+		}
+		return strings.Join(xs, "\n"), nil
+	}
+	if strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+	if f := strings.Fields(line); f[0] == "assert" && !strings.HasPrefix(strings.TrimSpace(line[len(f[0]):]), "=") {
+		return "", in.assert(ctx, line, len(f[0]))
+	}
+	name, expr := assignment(line)
+	v, err := in.eval(ctx, expr)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return v.String(), nil
+	}
+	in.vars[name] = v
+	if v.Matrix != nil {
+		return name + " =\n" + v.String(), nil
+	}
+	return name + " = " + v.String(), nil
+}
+
+// blank replaces the first n bytes of s with spaces, so that the
+// positions reported in errors are those of the statement s.
+func blank(s string, n int) string {
+	return strings.Repeat(" ", n) + s[n:]
+}
+
+// assignment splits a statement of the form "name = expression" into
+// the name and the expression, in which the name is blanked. Other
+// statements are returned as the expression.
+func assignment(line string) (string, string) {
+	i := strings.Index(line, "=")
+	if i < 0 || strings.HasPrefix(line[i:], "==") {
+		return "", line
+	}
+	ts, err := syntax.Algex.Lex(line[:i])
+	if err != nil || len(ts) != 1 || !ts[0].Ident {
+		return "", line
+	}
+	return ts[0].Text, blank(line, i+1)
+}
+
+// eval evaluates an expression with the variables and functions of in
+// under ctx.
+func (in *Interp) eval(ctx context.Context, expr string) (Value, error) {
+	v, err := syntax.Algex.EvalCtx(ctx, expr, in.scope())
+	return value(v), err
+}
+
+// ErrAssert indicates that an assert statement failed.
+var ErrAssert = errors.New("assertion failed")
+
+// assert checks an assert statement, of the form "assert a == b",
+// where n is the length of the "assert" keyword.
+func (in *Interp) assert(ctx context.Context, line string, n int) error {
+	i := strings.Index(line, "==")
+	if i < 0 {
+		return fmt.Errorf("at end: expected %q", "==")
+	}
+	a, err := in.eval(ctx, blank(line[:i], n))
+	if err != nil {
+		return err
+	}
+	b, err := in.eval(ctx, blank(line, i+2))
+	if err != nil {
+		return err
+	}
+	if !equal(a, b) {
+		return fmt.Errorf("%w: at %d: %s != %s", ErrAssert, i, oneLine(a), oneLine(b))
+	}
+	return nil
+}
+
+// oneLine displays a value on a single line.
+func oneLine(v Value) string {
+	if v.Matrix != nil {
+		return v.Matrix.String()
+	}
+	return v.String()
+}
+
+// AssertError lists the assertions that failed while running a script.
+type AssertError struct {
+	Failures []error
+}
+
+// Error lists the failures, one per line.
+func (e *AssertError) Error() string {
+	var xs []string
+	for _, f := range e.Failures {
+		xs = append(xs, f.Error())
+	}
+	return strings.Join(xs, "\n")
+}
+
+// Unwrap allows an AssertError to be detected with errors.Is(err,
+// ErrAssert).
+func (e *AssertError) Unwrap() error {
+	return ErrAssert
+}
+
+// Run executes a script read from r, one statement per line, writing
+// the displayed results to w. Execution stops at the first error, other
+// than a failed assertion. Execution continues after failed assertions
+// and Run then returns an *AssertError listing them. The name of the
+// script and a line number prefix error messages.
+func (in *Interp) Run(name string, r io.Reader, w io.Writer) error {
+	return in.RunCtx(context.Background(), name, r, w)
+}
+
+// RunCtx executes a script like Run, with each statement executed by
+// ExecCtx under ctx.
+func (in *Interp) RunCtx(ctx context.Context, name string, r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	var failed []error
+	for n := 1; sc.Scan(); n++ {
+		out, err := in.ExecCtx(ctx, sc.Text())
+		if errors.Is(err, ErrAssert) {
+			failed = append(failed, fmt.Errorf("%s:%d: %w", name, n, err))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, n, err)
+		}
+		if out != "" {
+			fmt.Fprintln(w, out)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if len(failed) != 0 {
+		return &AssertError{Failures: failed}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package interp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"algex/terms"
+)
+
+func TestExec(t *testing.T) {
+	in := New()
+	vs := []struct {
+		line, out string
+	}{
+		{line: "# a comment", out: ""},
+		{line: "x = (a + b)^2", out: "x = 2*a*b+a^2+b^2"},
+		{line: "x - 2*a*b", out: "a^2+b^2"},
+		{line: "expand(x/a)", out: "a+a^-1*b^2+2*b"},
+		{line: "collect(x*a + 3, a)", out: "a^3 + 2*b*a^2 + b^2*a + 3"},
+		{line: "collect(1 - a - b*a^2, a)", out: "-b*a^2 - a + 1"},
+		{line: "diff(x, a)", out: "2*a+2*b"},
+		{line: "diff(x*a, a, 2)", out: "6*a+4*b"},
+		{line: "diff(x, a, 20000000)", out: "0"},
+		{line: "diff(a^-1, a, 3)", out: "-6*a^-4"},
+		{line: "subst(x, a, c - b)", out: "c^2"},
+		{line: "subst(x, x, 1)", out: "2*a*b+a^2+b^2"},
+		{line: "m = [[1, a], [0, 1]]", out: "m =\n[ 1  a ]\n[ 0  1 ]"},
+		{line: "m^3 - m", out: "[ 0  2*a ]\n[ 0  0   ]"},
+		{line: "2*m*identity(2)", out: "[ 2  2*a ]\n[ 0  2   ]"},
+		{line: "RZ(t)", out: "[ ct  -st  0 ]\n[ st  ct   0 ]\n[ 0   0    1 ]"},
+		{line: "subst(RX(t)*RX(t), ct^2, 1 - st^2)", out: "[ 1  0         0        ]\n[ 0  1-2*st^2  -2*ct*st ]\n[ 0  2*ct*st   1-2*st^2 ]"},
+		{line: "transpose([[a, b]])", out: "[ a ]\n[ b ]"},
+		{line: "det(m)", out: "1"},
+		{line: "vars", out: "m =\n[ 1  a ]\n[ 0  1 ]\nx = 2*a*b+a^2+b^2"},
+	}
+	for i, v := range vs {
+		out, err := in.Exec(v.line)
+		if err != nil {
+			t.Errorf("[%d] %q failed: %v", i, v.line, err)
+		} else if out != v.out {
+			t.Errorf("[%d] %q got=%q want=%q", i, v.line, out, v.out)
+		}
+	}
+}
+
+func TestExecErrors(t *testing.T) {
+	in := New()
+	for i, line := range []string{
+		"1/(a + b)",
+		"foo(1)",
+		"[[1, 2], [3]]",
+		"[[1, 2]] + 1",
+		"[[1, 2]]^2",
+		"a^b",
+		"(a",
+		"a b",
+		"diff(a, 2)",
+		"subst(a, a + b, 1)",
+		"a $ b",
+		"x + ٣",
+		"transpose(a)",
+		"det([[1, 2]])",
+	} {
+		if out, err := in.Exec(line); err == nil {
+			t.Errorf("[%d] %q got=%q, want error", i, line, out)
+		}
+	}
+}
+
+func TestAssert(t *testing.T) {
+	in := New()
+	vs := []struct {
+		line string
+		ok   bool
+	}{
+		{line: "assert (a + b)^2 == a^2 + 2*a*b + b^2", ok: true},
+		{line: "assert a == b", ok: false},
+		{line: "assert [[a, 0]] == [[a, 0]]", ok: true},
+		{line: "assert [[a, 0]] == [[a], [0]]", ok: false},
+		{line: "assert [[1]] == 1", ok: false},
+		{line: "assert = 3", ok: true},
+	}
+	for i, v := range vs {
+		_, err := in.Exec(v.line)
+		if v.ok && err != nil {
+			t.Errorf("[%d] %q failed: %v", i, v.line, err)
+		} else if !v.ok && !errors.Is(err, ErrAssert) {
+			t.Errorf("[%d] %q got err=%v, want ErrAssert", i, v.line, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	err := New().Run("test", strings.NewReader("x = a\nassert x == b\nassert x == a\nassert x^2 == 1\nx^2\n"), &out)
+	if !errors.Is(err, ErrAssert) {
+		t.Errorf("got err=%v, want ErrAssert", err)
+	} else if got, want := err.Error(), "test:2: assertion failed: at 9: a != b\ntest:4: assertion failed: at 11: a^2 != 1"; got != want {
+		t.Errorf("got err=%q want=%q", got, want)
+	}
+	if got, want := out.String(), "x = a\na^2\n"; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	err = New().Run("test", strings.NewReader("x = a\nx +\nassert x == b\n"), &out)
+	if err == nil || errors.Is(err, ErrAssert) || !strings.HasPrefix(err.Error(), "test:2: ") {
+		t.Errorf("got err=%v, want syntax error on line 2", err)
+	}
+}
+
+func TestScripts(t *testing.T) {
+	fs, err := filepath.Glob("testdata/*.alg")
+	if err != nil || len(fs) == 0 {
+		t.Fatalf("no scripts found: %v", err)
+	}
+	for _, f := range fs {
+		r, err := os.Open(f)
+		if err != nil {
+			t.Fatalf("failed to open %q: %v", f, err)
+		}
+		var out bytes.Buffer
+		if err := New().Run(f, r, &out); err != nil {
+			t.Errorf("%v\n%s", err, out.String())
+		}
+		r.Close()
+	}
+}
+
+func TestExecCtx(t *testing.T) {
+	in := New()
+	ctx := terms.WithLimits(context.Background(), terms.Limits{MaxTerms: 10})
+	if got, err := in.ExecCtx(ctx, "x = (a+b)^3"); err != nil || got != "x = 3*a*b^2+3*a^2*b+a^3+b^3" {
+		t.Errorf("got=%q (%v)", got, err)
+	}
+	for i, line := range []string{
+		"(a+b+c)^4",
+		"x*x*x*x",
+		"[[a+b]]*[[a+b+c+d]]*[[a+b+c]]",
+		"subst(y^10, y, a+b)",
+	} {
+		if _, err := in.ExecCtx(ctx, line); !errors.Is(err, terms.ErrLimit) {
+			t.Errorf("[%d] %q got %v, want ErrLimit", i, line, err)
+		}
+	}
+	slow, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := in.ExecCtx(slow, "diff(a^-1, a, 20000000)"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("diff ran %v past its deadline", d)
+	}
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := in.ExecCtx(done, "x*x"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	err := in.RunCtx(done, "test", strings.NewReader("y = 1\ny*x\n"), &bytes.Buffer{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("run got %v, want context.Canceled", err)
+	}
+}
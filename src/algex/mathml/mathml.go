@@ -0,0 +1,165 @@
+// Package mathml exports expressions and matrices as MathML.
+//
+// Presentation MathML describes how an expression looks, and Content
+// MathML describes what it means. Both forms are returned as complete
+// <math> elements.
+package mathml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"algex/factor"
+	"algex/matrix"
+	"algex/terms"
+)
+
+// Namespace is the MathML XML namespace.
+const Namespace = "http://www.w3.org/1998/Math/MathML"
+
+// math wraps body in a top level MathML element.
+func math(body string) string {
+	return `<math xmlns="` + Namespace + `">` + body + `</math>`
+}
+
+// escape returns s with XML special characters escaped.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// Presentation returns the Presentation MathML form of an expression.
+func Presentation(e *terms.Exp) string {
+	return math(presentExp(e))
+}
+
+// PresentationMatrix returns the Presentation MathML form of a matrix.
+func PresentationMatrix(m *matrix.Matrix) string {
+	rows, cols := m.Dims()
+	var b strings.Builder
+	b.WriteString(`<mrow><mo>[</mo><mtable>`)
+	for r := 0; r < rows; r++ {
+		b.WriteString(`<mtr>`)
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			b.WriteString(`<mtd>` + presentExp(e) + `</mtd>`)
+		}
+		b.WriteString(`</mtr>`)
+	}
+	b.WriteString(`</mtable><mo>]</mo></mrow>`)
+	return math(b.String())
+}
+
+// presentNum returns the presentation form of a non-negative rational.
+func presentNum(n *big.Rat) string {
+	if n.IsInt() {
+		return `<mn>` + n.Num().String() + `</mn>`
+	}
+	return `<mfrac><mn>` + n.Num().String() + `</mn><mn>` + n.Denom().String() + `</mn></mfrac>`
+}
+
+// presentExp returns the presentation form of an expression.
+func presentExp(e *terms.Exp) string {
+	ts := e.Terms()
+	if len(ts) == 0 {
+		return `<mn>0</mn>`
+	}
+	var b strings.Builder
+	b.WriteString(`<mrow>`)
+	for i, t := range ts {
+		n := &big.Rat{}
+		n.Abs(t[0].Num())
+		if t[0].Num().Sign() < 0 {
+			b.WriteString(`<mo>-</mo>`)
+		} else if i != 0 {
+			b.WriteString(`<mo>+</mo>`)
+		}
+		var fs []string
+		if len(t) == 1 || n.Cmp(big.NewRat(1, 1)) != 0 {
+			fs = append(fs, presentNum(n))
+		}
+		for _, f := range t[1:] {
+			fs = append(fs, presentFactor(f))
+		}
+		b.WriteString(strings.Join(fs, `<mo>&#x2062;</mo>`))
+	}
+	b.WriteString(`</mrow>`)
+	return b.String()
+}
+
+// presentFactor returns the presentation form of a symbolic factor.
+func presentFactor(f factor.Value) string {
+	s := `<mi>` + escape(f.Sym()) + `</mi>`
+	switch p := f.Pow(); {
+	case p == 1:
+		return s
+	case p < 0:
+		return fmt.Sprintf(`<msup>%s<mrow><mo>-</mo><mn>%d</mn></mrow></msup>`, s, -p)
+	default:
+		return fmt.Sprintf(`<msup>%s<mn>%d</mn></msup>`, s, p)
+	}
+}
+
+// Content returns the Content MathML form of an expression.
+func Content(e *terms.Exp) string {
+	return math(contentExp(e))
+}
+
+// ContentMatrix returns the Content MathML form of a matrix.
+func ContentMatrix(m *matrix.Matrix) string {
+	rows, cols := m.Dims()
+	var b strings.Builder
+	b.WriteString(`<matrix>`)
+	for r := 0; r < rows; r++ {
+		b.WriteString(`<matrixrow>`)
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			b.WriteString(contentExp(e))
+		}
+		b.WriteString(`</matrixrow>`)
+	}
+	b.WriteString(`</matrix>`)
+	return math(b.String())
+}
+
+// contentNum returns the content form of a rational number.
+func contentNum(n *big.Rat) string {
+	if n.IsInt() {
+		return `<cn type="integer">` + n.Num().String() + `</cn>`
+	}
+	return `<cn type="rational">` + n.Num().String() + `<sep/>` + n.Denom().String() + `</cn>`
+}
+
+// contentExp returns the content form of an expression.
+func contentExp(e *terms.Exp) string {
+	ts := e.Terms()
+	if len(ts) == 0 {
+		return contentNum(&big.Rat{})
+	}
+	var xs []string
+	for _, t := range ts {
+		var fs []string
+		if len(t) == 1 || t[0].Num().Cmp(big.NewRat(1, 1)) != 0 {
+			fs = append(fs, contentNum(t[0].Num()))
+		}
+		for _, f := range t[1:] {
+			s := `<ci>` + escape(f.Sym()) + `</ci>`
+			if p := f.Pow(); p != 1 {
+				s = fmt.Sprintf(`<apply><power/>%s<cn type="integer">%d</cn></apply>`, s, p)
+			}
+			fs = append(fs, s)
+		}
+		if len(fs) == 1 {
+			xs = append(xs, fs[0])
+		} else {
+			xs = append(xs, `<apply><times/>`+strings.Join(fs, "")+`</apply>`)
+		}
+	}
+	if len(xs) == 1 {
+		return xs[0]
+	}
+	return `<apply><plus/>` + strings.Join(xs, "") + `</apply>`
+}
@@ -0,0 +1,61 @@
+package mathml
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/matrix"
+	"algex/terms"
+)
+
+func TestPresentation(t *testing.T) {
+	vs := []struct {
+		e *terms.Exp
+		s string
+	}{
+		{e: terms.NewExp(), s: `<mn>0</mn>`},
+		{
+			e: terms.NewExp([]factor.Value{factor.D(-1, 3), factor.S("x")}, []factor.Value{factor.Sp("y", -2)}),
+			s: `<mrow><mo>-</mo><mfrac><mn>1</mn><mn>3</mn></mfrac><mo>&#x2062;</mo><mi>x</mi><mo>+</mo><msup><mi>y</mi><mrow><mo>-</mo><mn>2</mn></mrow></msup></mrow>`,
+		},
+		{
+			e: terms.NewExp([]factor.Value{factor.D(2, 1)}, []factor.Value{factor.S("a<b")}),
+			s: `<mrow><mn>2</mn><mo>+</mo><mi>a&lt;b</mi></mrow>`,
+		},
+	}
+	for i, v := range vs {
+		if got, want := Presentation(v.e), math(v.s); got != want {
+			t.Errorf("[%d] got=%q want=%q", i, got, want)
+		}
+	}
+}
+
+func TestContent(t *testing.T) {
+	vs := []struct {
+		e *terms.Exp
+		s string
+	}{
+		{e: terms.NewExp(), s: `<cn type="integer">0</cn>`},
+		{e: terms.NewExp([]factor.Value{factor.S("x")}), s: `<ci>x</ci>`},
+		{
+			e: terms.NewExp([]factor.Value{factor.D(-1, 3), factor.S("x")}, []factor.Value{factor.Sp("y", -2)}),
+			s: `<apply><plus/><apply><times/><cn type="rational">-1<sep/>3</cn><ci>x</ci></apply><apply><power/><ci>y</ci><cn type="integer">-2</cn></apply></apply>`,
+		},
+	}
+	for i, v := range vs {
+		if got, want := Content(v.e), math(v.s); got != want {
+			t.Errorf("[%d] got=%q want=%q", i, got, want)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	m, _ := matrix.NewMatrix(1, 2)
+	m.Set(0, 0, terms.NewExp([]factor.Value{factor.S("a")}))
+	if got, want := PresentationMatrix(m), math(`<mrow><mo>[</mo><mtable><mtr><mtd><mrow><mi>a</mi></mrow></mtd><mtd><mn>0</mn></mtd></mtr></mtable><mo>]</mo></mrow>`); got != want {
+		t.Errorf("presentation got=%q want=%q", got, want)
+	}
+	if got, want := ContentMatrix(m), math(`<matrix><matrixrow><ci>a</ci><cn type="integer">0</cn></matrixrow></matrix>`); got != want {
+		t.Errorf("content got=%q want=%q", got, want)
+	}
+}
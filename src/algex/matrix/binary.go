@@ -0,0 +1,72 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"algex/terms"
+)
+
+// binaryVersion is the version of the binary matrix encoding. A matrix
+// is encoded as this version byte, uvarint rows and cols, and then its
+// elements, in row order, as a terms expression stream.
+const binaryVersion = 1
+
+// MarshalBinary encodes a matrix in a compact binary form.
+func (m *Matrix) MarshalBinary() ([]byte, error) {
+	b := []byte{binaryVersion}
+	b = binary.AppendUvarint(b, uint64(m.rows))
+	b = binary.AppendUvarint(b, uint64(m.cols))
+	buf := bytes.NewBuffer(b)
+	enc := terms.NewEncoder(buf)
+	for _, e := range m.data {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a matrix encoded by MarshalBinary.
+func (m *Matrix) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if v, err := r.ReadByte(); err != nil {
+		return fmt.Errorf("%w: empty matrix", terms.ErrBadEncoding)
+	} else if v != binaryVersion {
+		return fmt.Errorf("%w: unsupported matrix version %d", terms.ErrBadEncoding, v)
+	}
+	rows, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("%w: bad matrix rows", terms.ErrBadEncoding)
+	}
+	cols, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("%w: bad matrix cols", terms.ErrBadEncoding)
+	}
+	// Every element needs at least one byte, which bounds the
+	// dimensions a valid encoding can claim.
+	if rows == 0 || cols == 0 || rows > uint64(r.Len()) || cols > uint64(r.Len()) || rows*cols > uint64(r.Len()) {
+		return fmt.Errorf("%w: bad matrix dimensions %dx%d", terms.ErrBadEncoding, rows, cols)
+	}
+	n, err := NewMatrix(int(rows), int(cols))
+	if err != nil {
+		return err
+	}
+	dec := terms.NewDecoder(r)
+	for i := range n.data {
+		e, err := dec.Decode()
+		if err == io.EOF {
+			return fmt.Errorf("%w: truncated matrix", terms.ErrBadEncoding)
+		} else if err != nil {
+			return err
+		}
+		n.data[i] = e
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		return fmt.Errorf("%w: trailing data", terms.ErrBadEncoding)
+	}
+	*m = *n
+	return nil
+}
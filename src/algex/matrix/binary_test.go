@@ -0,0 +1,31 @@
+package matrix
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestBinary(t *testing.T) {
+	m, _ := NewMatrix(2, 3)
+	m.Set(0, 0, terms.NewExp([]factor.Value{factor.D(1, 3), factor.S("x")}))
+	m.Set(1, 2, terms.NewExp([]factor.Value{factor.Sp("x", -2)}, []factor.Value{factor.S("y")}))
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to marshal %v: %v", m, err)
+	}
+	n := &Matrix{}
+	if err := n.UnmarshalBinary(b); err != nil {
+		t.Fatalf("failed to unmarshal %v: %v", m, err)
+	}
+	if got, want := n.String(), m.String(); got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	if n.el(0, 1) != nil {
+		t.Errorf("nil element not preserved: got=%q", n.el(0, 1))
+	}
+	if err := n.UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Error("truncated matrix unmarshaled without error")
+	}
+}
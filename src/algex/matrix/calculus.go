@@ -0,0 +1,44 @@
+package matrix
+
+import (
+	"fmt"
+
+	"algex/terms"
+)
+
+// Jacobian returns the matrix of partial derivatives of the column
+// vector f with respect to each of syms. Element (i, j) is the
+// derivative of f[i] with respect to syms[j].
+func Jacobian(f *Matrix, syms ...string) (*Matrix, error) {
+	if f.cols != 1 {
+		return nil, fmt.Errorf("%w: need a column vector, not %dx%d", ErrDimension, f.rows, f.cols)
+	}
+	if len(syms) == 0 {
+		return nil, fmt.Errorf("no symbols to differentiate by")
+	}
+	j, _ := NewMatrix(f.rows, len(syms))
+	for r := 0; r < f.rows; r++ {
+		for c, s := range syms {
+			j.Set(r, c, terms.Diff(f.el(r, 0), s))
+		}
+	}
+	return j, nil
+}
+
+// Hessian returns the symmetric matrix of second partial derivatives
+// of e with respect to each pair of syms.
+func Hessian(e *terms.Exp, syms ...string) (*Matrix, error) {
+	if len(syms) == 0 {
+		return nil, fmt.Errorf("no symbols to differentiate by")
+	}
+	h, _ := NewMatrix(len(syms), len(syms))
+	for r, a := range syms {
+		d := terms.Diff(e, a)
+		for c := r; c < len(syms); c++ {
+			x := terms.Diff(d, syms[c])
+			h.Set(r, c, x)
+			h.Set(c, r, x)
+		}
+	}
+	return h, nil
+}
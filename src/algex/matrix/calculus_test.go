@@ -0,0 +1,63 @@
+package matrix
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestJacobian(t *testing.T) {
+	// Polar coordinates with c and s standing for cos(t) and sin(t),
+	// treated as independent.
+	f, _ := NewMatrix(2, 1)
+	f.Set(0, 0, terms.NewExp([]factor.Value{factor.S("r"), factor.S("c")}))
+	f.Set(1, 0, terms.NewExp([]factor.Value{factor.S("r"), factor.S("s")}))
+	vs := []struct {
+		syms []string
+		want string
+	}{
+		{[]string{"r"}, "[[c], [s]]"},
+		{[]string{"r", "c", "s"}, "[[c, r, 0], [s, 0, r]]"},
+	}
+	for i, v := range vs {
+		j, err := Jacobian(f, v.syms...)
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+			continue
+		}
+		if got := j.String(); got != v.want {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.want)
+		}
+	}
+	if _, err := Jacobian(symbols(2, 2), "a"); err == nil {
+		t.Error("non-column matrix accepted")
+	}
+	if _, err := Jacobian(f); err == nil {
+		t.Error("no symbols accepted")
+	}
+}
+
+func TestHessian(t *testing.T) {
+	// x^3*y + 2*x*y^2 + y
+	e := terms.NewExp(
+		[]factor.Value{factor.Sp("x", 3), factor.S("y")},
+		[]factor.Value{factor.D(2, 1), factor.S("x"), factor.Sp("y", 2)},
+		[]factor.Value{factor.S("y")},
+	)
+	h, err := Hessian(e, "x", "y")
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if got, want := h.String(), "[[6*x*y, 3*x^2+4*y], [3*x^2+4*y, 4*x]]"; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	// The Hessian is the Jacobian of the gradient.
+	g, _ := NewMatrix(2, 1)
+	g.Set(0, 0, terms.Diff(e, "x"))
+	g.Set(1, 0, terms.Diff(e, "y"))
+	j, _ := Jacobian(g, "x", "y")
+	if got, want := j.String(), h.String(); got != want {
+		t.Errorf("jacobian of gradient got=%q want=%q", got, want)
+	}
+}
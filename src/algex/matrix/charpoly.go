@@ -0,0 +1,115 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// CharPoly returns the characteristic polynomial, det(sym*I - m), of a
+// square matrix. It uses Berkowitz's algorithm, which needs no division.
+func (m *Matrix) CharPoly(sym string) (*terms.Exp, error) {
+	a, err := m.square()
+	if err != nil {
+		return nil, err
+	}
+	p := berkowitz(a)
+	x := terms.NewExp()
+	n := len(p) - 1
+	for i, c := range p {
+		if i == n {
+			x = terms.Add(x, c)
+			continue
+		}
+		x = terms.Add(x, terms.Mul(c, terms.NewExp([]factor.Value{factor.D(1, 1), factor.Sp(sym, n-i)})))
+	}
+	return x, nil
+}
+
+// berkowitz returns the coefficients of the characteristic polynomial
+// of a, starting with that of the highest power.
+func berkowitz(a [][]*terms.Exp) []*terms.Exp {
+	p := []*terms.Exp{terms.NewExp([]factor.Value{factor.D(1, 1)})}
+	for r := range a {
+		// Column of the Toeplitz matrix that maps the polynomial of
+		// the leading r x r submatrix to that of the (r+1) x (r+1)
+		// one: 1, -a_rr, -R*C, -R*M*C, ..., -R*M^(r-1)*C.
+		t := []*terms.Exp{p[0], terms.Sub(terms.NewExp(), a[r][r])}
+		c := make([]*terms.Exp, r)
+		for i := range c {
+			c[i] = a[i][r]
+		}
+		for k := 0; k < r; k++ {
+			var rc []*terms.Exp
+			for i, x := range c {
+				rc = append(rc, terms.Mul(a[r][i], x))
+			}
+			t = append(t, terms.Sub(terms.NewExp(), terms.Add(rc...)))
+			next := make([]*terms.Exp, r)
+			for i := range next {
+				var xs []*terms.Exp
+				for j, x := range c {
+					xs = append(xs, terms.Mul(a[i][j], x))
+				}
+				next[i] = terms.Add(xs...)
+			}
+			c = next
+		}
+		q := make([]*terms.Exp, len(p)+1)
+		for i := range q {
+			var xs []*terms.Exp
+			for j := 0; j <= i && j < len(p); j++ {
+				xs = append(xs, terms.Mul(t[i-j], p[j]))
+			}
+			q[i] = terms.Add(xs...)
+		}
+		p = q
+	}
+	return p
+}
+
+// Poly evaluates the polynomial p in the symbol sym at the square
+// matrix m. The coefficients of p may contain other symbols, which
+// multiply the matrix powers of m. Constant terms are multiplied by the
+// identity matrix.
+func (m *Matrix) Poly(p *terms.Exp, sym string) (*Matrix, error) {
+	if m.rows != m.cols {
+		return nil, fmt.Errorf("%w: need a square matrix, not %dx%d", ErrDimension, m.rows, m.cols)
+	}
+	cs := p.Collect(sym)
+	var ps []int
+	for k := range cs {
+		if k < 0 {
+			return nil, fmt.Errorf("%q has a negative power of %q", p, sym)
+		}
+		ps = append(ps, k)
+	}
+	sort.Ints(ps)
+	id, _ := Identity(m.rows)
+	a, _ := NewMatrix(m.rows, m.cols)
+	if len(ps) == 0 {
+		return a, nil
+	}
+	// Horner's method, from the highest power down.
+	for k := ps[len(ps)-1]; k >= 0; k-- {
+		if k != ps[len(ps)-1] {
+			a = a.Mx(m)
+		}
+		if c, ok := cs[k]; ok {
+			a = a.Add(id, c)
+		}
+	}
+	return a, nil
+}
+
+// IsZero indicates that every element of m is zero.
+func (m *Matrix) IsZero() bool {
+	for _, e := range m.data {
+		if !e.IsZero() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package matrix
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestCharPoly(t *testing.T) {
+	n := func(x int64) []factor.Value { return []factor.Value{factor.D(x, 1)} }
+	s := func(x string) []factor.Value { return []factor.Value{factor.S(x)} }
+	vs := []struct {
+		m    *Matrix
+		want string
+	}{
+		{m: literal([][][]factor.Value{{s("a")}}), want: "-a+x"},
+		{m: literal([][][]factor.Value{{s("a"), s("b")}, {s("c"), s("d")}}), want: "a*d-a*x-b*c-d*x+x^2"},
+		{m: identity(3), want: "-1+3*x-3*x^2+x^3"},
+		{m: literal([][][]factor.Value{{nil, n(1), nil}, {nil, nil, n(1)}, {nil, nil, nil}}), want: "x^3"},
+		{m: literal([][][]factor.Value{{n(2), nil, nil, nil}, {nil, n(3), nil, nil}, {nil, nil, n(1), n(1)}, {nil, nil, nil, n(1)}}), want: "6-17*x+17*x^2-7*x^3+x^4"},
+	}
+	for i, v := range vs {
+		p, err := v.m.CharPoly("x")
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+			continue
+		}
+		if got := p.String(); got != v.want {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.want)
+		}
+		// Cayley-Hamilton: every matrix satisfies its characteristic
+		// polynomial.
+		z, err := v.m.Poly(p, "x")
+		if err != nil {
+			t.Errorf("[%d] evaluation failed: %v", i, err)
+		} else if !z.IsZero() {
+			t.Errorf("[%d] p(m)=%v, want zero", i, z)
+		}
+	}
+	if _, err := symbols(2, 3).CharPoly("x"); err == nil {
+		t.Error("non-square matrix accepted")
+	}
+}
+
+func TestPoly(t *testing.T) {
+	m := symbols(2, 2)
+	x := terms.NewExp([]factor.Value{factor.S("x")})
+	if got, err := m.Poly(x, "x"); err != nil || got.String() != m.String() {
+		t.Errorf("got=%v (%v) want=%v", got, err, m)
+	}
+	sq := terms.NewExp([]factor.Value{factor.D(1, 1), factor.Sp("x", 2)})
+	if got, err := m.Poly(sq, "x"); err != nil || got.String() != m.Mx(m).String() {
+		t.Errorf("got=%v (%v) want=%v", got, err, m.Mx(m))
+	}
+	// The minimal polynomial of a diagonal matrix with a repeated
+	// eigenvalue has lower degree than its characteristic polynomial.
+	a := []factor.Value{factor.S("a")}
+	d := literal([][][]factor.Value{{a, nil}, {nil, a}})
+	if got, err := d.Poly(terms.Sub(x, terms.NewExp(a)), "x"); err != nil || !got.IsZero() {
+		t.Errorf("got=%v (%v) want zero", got, err)
+	}
+	inv := terms.NewExp([]factor.Value{factor.D(1, 1), factor.Sp("x", -1)})
+	if _, err := m.Poly(inv, "x"); err == nil {
+		t.Error("negative power accepted")
+	}
+}
@@ -0,0 +1,69 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// mulEl computes the (r, c) element of m x n under ctx.
+func mulEl(ctx context.Context, m, n *Matrix, r, c int) (*terms.Exp, error) {
+	var e []*terms.Exp
+	for i := 0; i < m.cols; i++ {
+		x, y := m.el(r, i), n.el(i, c)
+		if x != nil && y != nil {
+			p, err := terms.MulCtx(ctx, x, y)
+			if err != nil {
+				return nil, err
+			}
+			e = append(e, p)
+		}
+	}
+	s := terms.Add(e...)
+	if err := terms.LimitsOf(ctx).Check(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MulCtx multiplies m x n like Mul. It fails if ctx is done or the
+// terms.Limits of ctx are exceeded.
+func (m *Matrix) MulCtx(ctx context.Context, n *Matrix) (*Matrix, error) {
+	if m.cols != n.rows {
+		return nil, fmt.Errorf("%w: a cols(%d) != b rows(%d)", ErrDimension, m.cols, n.rows)
+	}
+	a, err := NewMatrix(m.rows, n.cols)
+	if err != nil {
+		return nil, err
+	}
+	for r := 0; r < a.rows; r++ {
+		for c := 0; c < a.cols; c++ {
+			e, err := mulEl(ctx, m, n, r, c)
+			if err != nil {
+				return nil, err
+			}
+			a.Set(r, c, e)
+		}
+	}
+	return a, nil
+}
+
+// SubstituteCtx performs a substitution on all elements of a matrix like
+// Substitute. It fails if ctx is done or the terms.Limits of ctx are
+// exceeded.
+func (m *Matrix) SubstituteCtx(ctx context.Context, b []factor.Value, s *terms.Exp) (*Matrix, error) {
+	n, _ := NewMatrix(m.rows, m.cols)
+	for i, e := range m.data {
+		if e == nil {
+			continue
+		}
+		x, err := terms.SubstituteCtx(ctx, e, b, s)
+		if err != nil {
+			return nil, err
+		}
+		n.data[i] = x
+	}
+	return n, nil
+}
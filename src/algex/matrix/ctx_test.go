@@ -0,0 +1,45 @@
+package matrix
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestCtx(t *testing.T) {
+	m := symbols(3, 3)
+	ctx := context.Background()
+	x, err := m.MulCtx(ctx, m)
+	if err != nil || x.String() != m.Mx(m).String() {
+		t.Errorf("MulCtx got=%v (%v)", x, err)
+	}
+	b := []factor.Value{factor.S("a")}
+	s := terms.NewExp([]factor.Value{factor.S("x")}, []factor.Value{factor.S("y")})
+	y, err := m.SubstituteCtx(ctx, b, s)
+	if z, _ := m.Substitute(b, s); err != nil || y.String() != z.String() {
+		t.Errorf("SubstituteCtx got=%v (%v)", y, err)
+	}
+	if _, err := m.MulCtx(ctx, symbols(2, 2)); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want ErrDimension", err)
+	}
+
+	lim := terms.WithLimits(ctx, terms.Limits{MaxTerms: 2})
+	if _, err := m.MulCtx(lim, m); !errors.Is(err, terms.ErrLimit) {
+		t.Errorf("MulCtx got %v, want ErrLimit", err)
+	}
+	if _, err := x.SubstituteCtx(terms.WithLimits(ctx, terms.Limits{MaxDegree: 1}), b, s); !errors.Is(err, terms.ErrLimit) {
+		t.Errorf("SubstituteCtx got %v, want ErrLimit", err)
+	}
+
+	done, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := m.MulCtx(done, m); !errors.Is(err, context.Canceled) {
+		t.Errorf("MulCtx got %v, want context.Canceled", err)
+	}
+	if _, err := m.SubstituteCtx(done, b, s); !errors.Is(err, context.Canceled) {
+		t.Errorf("SubstituteCtx got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,110 @@
+package matrix
+
+import (
+	"fmt"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// maxCofactor is the largest dimension for which Det uses cofactor
+// expansion.
+const maxCofactor = 3
+
+// square returns the elements of a square matrix as rows of
+// expressions, with nil elements replaced by zero.
+func (m *Matrix) square() ([][]*terms.Exp, error) {
+	if m.rows != m.cols {
+		return nil, fmt.Errorf("%w: need a square matrix, not %dx%d", ErrDimension, m.rows, m.cols)
+	}
+	a := make([][]*terms.Exp, m.rows)
+	for r := range a {
+		a[r] = make([]*terms.Exp, m.cols)
+		for c := range a[r] {
+			if e := m.el(r, c); e != nil {
+				a[r][c] = e
+			} else {
+				a[r][c] = terms.NewExp()
+			}
+		}
+	}
+	return a, nil
+}
+
+// Det returns the determinant of a square matrix. Unset elements are
+// treated as zero. Small matrices are expanded by cofactors and larger
+// ones are reduced with Bareiss' fraction-free elimination.
+func (m *Matrix) Det() (*terms.Exp, error) {
+	a, err := m.square()
+	if err != nil {
+		return nil, err
+	}
+	if len(a) <= maxCofactor {
+		return cofactor(a), nil
+	}
+	return bareiss(a)
+}
+
+// cofactor computes a determinant by cofactor expansion along the first
+// row.
+func cofactor(a [][]*terms.Exp) *terms.Exp {
+	n := len(a)
+	if n == 1 {
+		return terms.Add(a[0][0])
+	}
+	var es []*terms.Exp
+	for c := 0; c < n; c++ {
+		if a[0][c].IsZero() {
+			continue
+		}
+		var minor [][]*terms.Exp
+		for _, row := range a[1:] {
+			var x []*terms.Exp
+			x = append(x, row[:c]...)
+			minor = append(minor, append(x, row[c+1:]...))
+		}
+		e := terms.Mul(a[0][c], cofactor(minor))
+		if c%2 == 1 {
+			e = terms.Sub(terms.NewExp(), e)
+		}
+		es = append(es, e)
+	}
+	return terms.Add(es...)
+}
+
+// bareiss computes a determinant by fraction-free Gaussian elimination.
+// Every division it performs is exact. The rows of a are overwritten.
+func bareiss(a [][]*terms.Exp) (*terms.Exp, error) {
+	n := len(a)
+	negate := false
+	prev := terms.NewExp([]factor.Value{factor.D(1, 1)})
+	for k := 0; k < n-1; k++ {
+		if a[k][k].IsZero() {
+			p := k + 1
+			for p < n && a[p][k].IsZero() {
+				p++
+			}
+			if p == n {
+				return terms.NewExp(), nil
+			}
+			a[k], a[p] = a[p], a[k]
+			negate = !negate
+		}
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j < n; j++ {
+				x := terms.Sub(terms.Mul(a[k][k], a[i][j]), terms.Mul(a[i][k], a[k][j]))
+				q, err := terms.Quo(x, prev)
+				if err != nil {
+					return nil, fmt.Errorf("elimination failed: %v", err)
+				}
+				a[i][j] = q
+			}
+		}
+		prev = a[k][k]
+	}
+	d := terms.Add(a[n-1][n-1])
+	if negate {
+		d = terms.Sub(terms.NewExp(), d)
+	}
+	return d, nil
+}
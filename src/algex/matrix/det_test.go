@@ -0,0 +1,85 @@
+package matrix
+
+import (
+	"testing"
+
+	"algex/factor"
+)
+
+func TestDet(t *testing.T) {
+	m, _ := NewMatrix(2, 2)
+	m.Set(0, 0, exp(factor.S("a")))
+	m.Set(0, 1, exp(factor.S("b")))
+	m.Set(1, 0, exp(factor.S("c")))
+	m.Set(1, 1, exp(factor.S("d")))
+	if d, err := m.Det(); err != nil {
+		t.Errorf("failed to compute det(%v): %v", m, err)
+	} else if got, want := d.String(), "a*d-b*c"; got != want {
+		t.Errorf("det(%v) got=%q want=%q", m, got, want)
+	}
+
+	id, _ := Identity(5)
+	if d, err := id.Det(); err != nil || d.String() != "1" {
+		t.Errorf("det(I5) got=%v, %v", d, err)
+	}
+
+	r, _ := NewMatrix(2, 3)
+	if d, err := r.Det(); err == nil {
+		t.Errorf("det of non-square matrix got=%q", d)
+	}
+}
+
+func TestBareiss(t *testing.T) {
+	// A 4x4 symbolic matrix with a zero leading element, so a row
+	// exchange is needed.
+	syms := [][]string{
+		{"", "a", "b", "c"},
+		{"d", "e", "", "f"},
+		{"g", "", "h", "i"},
+		{"j", "k", "l", ""},
+	}
+	m, _ := NewMatrix(4, 4)
+	for r, row := range syms {
+		for c, s := range row {
+			if s != "" {
+				m.Set(r, c, exp(factor.S(s)))
+			}
+		}
+	}
+	a, _ := m.square()
+	want := cofactor(a)
+	a, _ = m.square()
+	got, err := bareiss(a)
+	if err != nil {
+		t.Fatalf("bareiss failed: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("bareiss got=%q want=%q", got, want)
+	}
+	if d, err := m.Det(); err != nil || d.String() != want.String() {
+		t.Errorf("det got=%v, %v want=%q", d, err, want)
+	}
+
+	// A singular matrix.
+	s, _ := NewMatrix(4, 4)
+	for c := 0; c < 4; c++ {
+		s.Set(0, c, exp(factor.S("x")))
+		s.Set(1, c, exp(factor.D(2, 1), factor.S("x")))
+		s.Set(2, c, exp(factor.Sp("y", c)))
+		s.Set(3, c, exp(factor.Sp("z", c)))
+	}
+	if d, err := s.Det(); err != nil || d.String() != "0" {
+		t.Errorf("singular det got=%v, %v", d, err)
+	}
+}
+
+func TestLaurentDet(t *testing.T) {
+	for n := 2; n <= 5; n++ {
+		m := laurent(n)
+		a, _ := m.square()
+		want := cofactor(a)
+		if d, err := m.Det(); err != nil || d.String() != want.String() {
+			t.Errorf("[%d] det got=%v, %v want=%q", n, d, err, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package matrix
+
+import (
+	"algex/factor"
+	"algex/terms"
+)
+
+// Echelon is the reduced row echelon form of a matrix.
+type Echelon struct {
+	// R and Den give the reduced row echelon form, R/Den. Den is 1
+	// unless some element of R is not exactly divisible by it.
+	R   *Matrix
+	Den *terms.Exp
+	// Pivots lists the pivot column of each non-zero row of R.
+	Pivots []int
+	// Conditions lists expressions assumed to be non-zero when they
+	// were used as pivots. The form is only valid where none of them
+	// vanish, so their roots locate the singular configurations.
+	Conditions []*terms.Exp
+}
+
+// RREF returns the reduced row echelon form of m. Every element is
+// treated as non-zero unless it is identically zero, and any pivot that
+// is not a number is listed in the Conditions of the result.
+func (m *Matrix) RREF() (*Echelon, error) {
+	return m.RREFGeneric()
+}
+
+// RREFGeneric returns the reduced row echelon form of m, treating the
+// listed symbols as generic, that is non-zero. Pivots that are
+// monomials in generic symbols are preferred, and other pivots are
+// listed in the Conditions of the result after removing any generic
+// monomial factor.
+func (m *Matrix) RREFGeneric(generic ...string) (*Echelon, error) {
+	g := make(map[string]bool)
+	for _, s := range generic {
+		g[s] = true
+	}
+	e, err := m.echelon(g)
+	if err != nil {
+		return nil, err
+	}
+	r, _ := NewMatrix(m.rows, m.cols)
+	for i, row := range e.a {
+		for j, x := range row {
+			if !x.IsZero() {
+				r.Set(i, j, x)
+			}
+		}
+	}
+	ech := &Echelon{
+		R:          r,
+		Den:        e.d,
+		Pivots:     e.pivots,
+		Conditions: e.conds,
+	}
+	if x, ok := divide(r, e.d); ok {
+		ech.R, ech.Den = x, terms.NewExp([]factor.Value{factor.D(1, 1)})
+	}
+	return ech, nil
+}
+
+// echelon eliminates a copy of the rows of m.
+func (m *Matrix) echelon(generic map[string]bool) (*echelon, error) {
+	return eliminate(m.rowsOf(), m.cols, generic)
+}
+
+// Rank returns the rank of m for generic values of its symbols.
+func (m *Matrix) Rank() (int, error) {
+	e, err := m.echelon(nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(e.pivots), nil
+}
+
+// NullSpace returns a basis of column vectors for the nullspace of m,
+// valid for generic values of its symbols.
+func (m *Matrix) NullSpace() ([]*Matrix, error) {
+	e, err := m.echelon(nil)
+	if err != nil {
+		return nil, err
+	}
+	basis, _ := e.null(m.cols)
+	return basis, nil
+}
+
+// ColumnSpace returns a basis for the column space of m, valid for
+// generic values of its symbols. The basis is the pivot columns of m.
+func (m *Matrix) ColumnSpace() ([]*Matrix, error) {
+	e, err := m.echelon(nil)
+	if err != nil {
+		return nil, err
+	}
+	var basis []*Matrix
+	for _, c := range e.pivots {
+		v, err := m.Col(c)
+		if err != nil {
+			return nil, err
+		}
+		basis = append(basis, v)
+	}
+	return basis, nil
+}
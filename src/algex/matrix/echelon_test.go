@@ -0,0 +1,180 @@
+package matrix
+
+import (
+	"fmt"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestRREF(t *testing.T) {
+	n := func(x int64) []factor.Value { return []factor.Value{factor.D(x, 1)} }
+	s := func(x string) []factor.Value { return []factor.Value{factor.S(x)} }
+	vs := []struct {
+		m       *Matrix
+		generic []string
+		r, den  string
+		pivots  string
+		conds   string
+	}{
+		{
+			m:      literal([][][]factor.Value{{n(1), n(2), n(3)}, {n(2), n(4), n(7)}}),
+			r:      "[[1, 2, 0], [0, 0, 1]]",
+			den:    "1",
+			pivots: "[0 2]",
+			conds:  "[]",
+		},
+		{
+			m:      literal([][][]factor.Value{{s("a"), s("b")}, {s("c"), s("d")}}),
+			r:      "[[1, 0], [0, 1]]",
+			den:    "1",
+			pivots: "[0 1]",
+			conds:  "[a a*d-b*c]",
+		},
+		{
+			m:       literal([][][]factor.Value{{s("a"), s("b")}, {s("c"), s("d")}}),
+			generic: []string{"a"},
+			r:       "[[1, 0], [0, 1]]",
+			den:     "1",
+			pivots:  "[0 1]",
+			conds:   "[a*d-b*c]",
+		},
+		{
+			m:       literal([][][]factor.Value{{s("a"), s("b"), s("e")}, {s("c"), s("d"), s("f")}}),
+			generic: []string{"a", "b", "c", "d"},
+			r:       "[[a*d-b*c, 0, -b*f+d*e], [0, a*d-b*c, a*f-c*e]]",
+			den:     "a*d-b*c",
+			pivots:  "[0 1]",
+			conds:   "[a*d-b*c]",
+		},
+		{
+			m:       literal([][][]factor.Value{{s("x"), s("y")}, {[]factor.Value{factor.D(2, 1), factor.S("x")}, []factor.Value{factor.D(2, 1), factor.S("y")}}}),
+			generic: []string{"x"},
+			r:       "[[1, x^-1*y], [0, 0]]",
+			den:     "1",
+			pivots:  "[0]",
+			conds:   "[]",
+		},
+		{
+			m:       literal([][][]factor.Value{{s("a"), s("a")}, {s("a"), s("b")}}),
+			generic: []string{"a"},
+			r:       "[[1, 0], [0, 1]]",
+			den:     "1",
+			pivots:  "[0 1]",
+			conds:   "[-a+b]",
+		},
+	}
+	for i, v := range vs {
+		e, err := v.m.RREFGeneric(v.generic...)
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+			continue
+		}
+		if got := e.R.String(); got != v.r {
+			t.Errorf("[%d] got r=%q want=%q", i, got, v.r)
+		}
+		if got := e.Den.String(); got != v.den {
+			t.Errorf("[%d] got den=%q want=%q", i, got, v.den)
+		}
+		if got := fmt.Sprint(e.Pivots); got != v.pivots {
+			t.Errorf("[%d] got pivots=%s want=%s", i, got, v.pivots)
+		}
+		if got := fmt.Sprint(e.Conditions); got != v.conds {
+			t.Errorf("[%d] got conditions=%s want=%s", i, got, v.conds)
+		}
+	}
+}
+
+func TestSpaces(t *testing.T) {
+	n := func(x int64) []factor.Value { return []factor.Value{factor.D(x, 1)} }
+	s := func(x string) []factor.Value { return []factor.Value{factor.S(x)} }
+	vs := []struct {
+		m          *Matrix
+		rank       int
+		null, cols string
+	}{
+		{
+			m:    identity(3),
+			rank: 3,
+			null: "[]",
+			cols: "[[[1], [0], [0]] [[0], [1], [0]] [[0], [0], [1]]]",
+		},
+		{
+			m:    literal([][][]factor.Value{{s("a"), s("b")}, {s("a"), s("b")}}),
+			rank: 1,
+			null: "[[[-b], [a]]]",
+			cols: "[[[a], [a]]]",
+		},
+		{
+			m:    literal([][][]factor.Value{{n(1), n(2), n(3)}, {n(2), n(4), n(6)}}),
+			rank: 1,
+			null: "[[[-2], [1], [0]] [[-3], [0], [1]]]",
+			cols: "[[[1], [2]]]",
+		},
+	}
+	for i, v := range vs {
+		if got, err := v.m.Rank(); err != nil || got != v.rank {
+			t.Errorf("[%d] got rank=%d (%v) want=%d", i, got, err, v.rank)
+		}
+		ns, err := v.m.NullSpace()
+		if err != nil {
+			t.Errorf("[%d] nullspace failed: %v", i, err)
+		} else if got := fmt.Sprint(ns); got != v.null {
+			t.Errorf("[%d] got null=%s want=%s", i, got, v.null)
+		}
+		for j, x := range ns {
+			z := v.m.Mx(x)
+			rows, _ := z.Dims()
+			for r := 0; r < rows; r++ {
+				if !z.el(r, 0).IsZero() {
+					t.Errorf("[%d] (m*null[%d])[%d]=%v, want 0", i, j, r, z.el(r, 0))
+				}
+			}
+		}
+		cs, err := v.m.ColumnSpace()
+		if err != nil {
+			t.Errorf("[%d] column space failed: %v", i, err)
+		} else if got := fmt.Sprint(cs); got != v.cols {
+			t.Errorf("[%d] got cols=%s want=%s", i, got, v.cols)
+		}
+	}
+}
+
+func TestLaurentEchelon(t *testing.T) {
+	// Every row past the first two combines them with Laurent
+	// coefficients, so the rank is 2.
+	m := laurent(5)
+	for r := 2; r < 5; r++ {
+		for c := 0; c < 5; c++ {
+			m.Set(r, c, terms.Add(
+				terms.Mul(m.el(0, c), exp(factor.Sp("x", -r))),
+				terms.Mul(m.el(1, c), exp(factor.Sp("y", 1-r)))))
+		}
+	}
+	e, err := m.RREF()
+	if err != nil {
+		t.Fatalf("RREF failed: %v", err)
+	}
+	if got := fmt.Sprint(e.Pivots); got != "[0 1]" {
+		t.Errorf("got pivots=%s want=[0 1]", got)
+	}
+	if r, err := m.Rank(); err != nil || r != 2 {
+		t.Errorf("got rank=%d (%v) want=2", r, err)
+	}
+	ns, err := m.NullSpace()
+	if err != nil || len(ns) != 3 {
+		t.Fatalf("got null space %v (%v), want three vectors", ns, err)
+	}
+	for i, v := range ns {
+		if p := m.Mx(v); !p.IsZero() {
+			t.Errorf("m*null[%d] got=%v, want zero", i, p)
+		}
+	}
+	if cs, err := m.ColumnSpace(); err != nil || len(cs) != 2 {
+		t.Errorf("got column space %v (%v), want two vectors", cs, err)
+	}
+	if r, err := singular(5).Rank(); err != nil || r != 4 {
+		t.Errorf("singular got rank=%d (%v) want=4", r, err)
+	}
+}
@@ -0,0 +1,186 @@
+package matrix
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// Eigenvalue is an eigenvalue of a matrix and its eigenvectors.
+type Eigenvalue struct {
+	Value *terms.Exp
+	// Multiplicity is the algebraic multiplicity of the eigenvalue.
+	Multiplicity int
+	// Vectors is a basis of column vectors for the eigenspace,
+	// valid for generic values of the symbols of the matrix.
+	Vectors []*Matrix
+}
+
+// Eigensystem holds the eigenvalues of a matrix that could be found.
+type Eigensystem struct {
+	Values []Eigenvalue
+	// Factors holds what remains of the characteristic polynomial
+	// once the roots in Values are divided out. Its roots are not
+	// found, so a matrix with irrational or complex eigenvalues, such
+	// as a rotation, reports them here as an unsolved polynomial.
+	// The remainder is not factored further: two quadratics are
+	// reported as a single quartic.
+	Factors []*terms.Exp
+}
+
+// maxDivisors bounds the size of the integers whose divisors are tried
+// as rational roots.
+const maxDivisors = 1 << 40
+
+// Eigen finds the eigenvalues of a square matrix as the roots of its
+// characteristic polynomial in sym, which must not be a symbol of m.
+// Candidate roots are zero, the diagonal elements of m and, when the
+// polynomial has numerical coefficients, the rationals allowed by the
+// rational root theorem. Each root is divided out of the polynomial and
+// what remains is returned in the Factors of the result. Only these
+// candidates are found: roots that need a radical, such as those of
+// x^2+1 or x^2-2, are never returned in Values. Symbolic identities,
+// such as c^2+s^2 = 1, are not applied.
+func (m *Matrix) Eigen(sym string) (*Eigensystem, error) {
+	for _, e := range m.data {
+		for _, s := range e.Symbols() {
+			if s == sym {
+				return nil, fmt.Errorf("matrix contains eigenvalue symbol %q", sym)
+			}
+		}
+	}
+	p, err := m.CharPoly(sym)
+	if err != nil {
+		return nil, err
+	}
+	x := terms.NewExp([]factor.Value{factor.S(sym)})
+	es := &Eigensystem{}
+	seen := make(map[string]bool)
+	for _, r := range candidates(m, p, sym) {
+		if seen[r.String()] {
+			continue
+		}
+		seen[r.String()] = true
+		n := 0
+		for degree(p, sym) > 0 {
+			var q *terms.Exp
+			if r.IsZero() {
+				if _, ok := p.Collect(sym)[0]; ok {
+					break
+				}
+				q, _ = terms.Quo(p, x)
+			} else if q, err = terms.Quo(p, terms.Sub(x, r)); err != nil {
+				break
+			}
+			p = q
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		vs, err := m.eigenvectors(r)
+		if err != nil {
+			return nil, err
+		}
+		es.Values = append(es.Values, Eigenvalue{Value: r, Multiplicity: n, Vectors: vs})
+	}
+	if degree(p, sym) > 0 {
+		es.Factors = append(es.Factors, p)
+	}
+	return es, nil
+}
+
+// degree returns the highest power of sym in p.
+func degree(p *terms.Exp, sym string) int {
+	d := 0
+	for k := range p.Collect(sym) {
+		if k > d {
+			d = k
+		}
+	}
+	return d
+}
+
+// candidates returns possible roots of the characteristic polynomial p
+// of m.
+func candidates(m *Matrix, p *terms.Exp, sym string) []*terms.Exp {
+	rs := []*terms.Exp{terms.NewExp()}
+	for i := 0; i < m.rows; i++ {
+		if e := m.el(i, i); e != nil {
+			rs = append(rs, e)
+		}
+	}
+	// Rational root theorem: scale the coefficients to integers and
+	// try ±a/b where a divides the lowest and b the highest.
+	cs := p.Collect(sym)
+	var ks []int
+	den := big.NewInt(1)
+	for k, c := range cs {
+		if len(c.Symbols()) != 0 {
+			return rs
+		}
+		ks = append(ks, k)
+		n, _ := c.AsNumber()
+		den.Mul(den, n.Denom())
+	}
+	sort.Ints(ks)
+	integer := func(k int) *big.Int {
+		n, _ := cs[k].AsNumber()
+		x := new(big.Rat).Mul(n, new(big.Rat).SetInt(den))
+		return new(big.Int).Abs(x.Num())
+	}
+	for _, a := range divisors(integer(ks[0])) {
+		for _, b := range divisors(integer(ks[len(ks)-1])) {
+			r := big.NewRat(a, b)
+			rs = append(rs, terms.NewExp([]factor.Value{factor.R(r)}))
+			rs = append(rs, terms.NewExp([]factor.Value{factor.R(r.Neg(r))}))
+		}
+	}
+	return rs
+}
+
+// divisors returns the positive divisors of n, or nothing if n is too
+// large.
+func divisors(n *big.Int) []int64 {
+	if !n.IsInt64() || n.Int64() > maxDivisors {
+		return nil
+	}
+	x := n.Int64()
+	var ds []int64
+	for d := int64(1); d*d <= x; d++ {
+		if x%d == 0 {
+			ds = append(ds, d)
+			if d*d != x {
+				ds = append(ds, x/d)
+			}
+		}
+	}
+	return ds
+}
+
+// eigenvectors returns a basis of the nullspace of m - r*I. Each vector
+// is divided by its free element when that is a number, or a polynomial
+// that divides the others exactly.
+func (m *Matrix) eigenvectors(r *terms.Exp) ([]*Matrix, error) {
+	id, _ := Identity(m.rows)
+	a, err := m.Sum(id, terms.Sub(terms.NewExp(), r))
+	if err != nil {
+		return nil, err
+	}
+	e, err := a.echelon(nil)
+	if err != nil {
+		return nil, err
+	}
+	vs, free := e.null(m.cols)
+	for i, v := range vs {
+		if d := v.el(free[i], 0); len(d.Terms()) > 1 || len(d.Symbols()) == 0 {
+			if x, ok := divide(v, d); ok {
+				vs[i] = x
+			}
+		}
+	}
+	return vs, nil
+}
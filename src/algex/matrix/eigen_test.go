@@ -0,0 +1,81 @@
+package matrix
+
+import (
+	"fmt"
+	"testing"
+
+	"algex/factor"
+)
+
+func TestEigen(t *testing.T) {
+	n := func(x int64) []factor.Value { return []factor.Value{factor.D(x, 1)} }
+	s := func(x string) []factor.Value { return []factor.Value{factor.S(x)} }
+	vs := []struct {
+		m       *Matrix
+		want    string
+		factors string
+	}{
+		{
+			m:    literal([][][]factor.Value{{n(2), n(1)}, {n(1), n(2)}}),
+			want: "1 x1 [[[-1], [1]]]; 3 x1 [[[1], [1]]]",
+		},
+		{
+			m:    literal([][][]factor.Value{{s("a"), n(1)}, {nil, s("b")}}),
+			want: "a x1 [[[1], [0]]]; b x1 [[[-1], [a-b]]]",
+		},
+		{
+			m:    literal([][][]factor.Value{{nil, n(1)}, {nil, nil}}),
+			want: "0 x2 [[[1], [0]]]",
+		},
+		{
+			m:       literal([][][]factor.Value{{n(1), nil, nil}, {nil, nil, n(-1)}, {nil, n(1), nil}}),
+			want:    "1 x1 [[[1], [0], [0]]]",
+			factors: "[1+x^2]",
+		},
+		{
+			m:       literal([][][]factor.Value{{n(1), n(2)}, {n(3), n(4)}}),
+			want:    "",
+			factors: "[-2-5*x+x^2]",
+		},
+		{
+			// A rotation has complex eigenvalues, which are left
+			// in its characteristic polynomial.
+			m:       literal([][][]factor.Value{{s("c"), {factor.D(-1, 1), factor.S("s")}}, {s("s"), s("c")}}),
+			want:    "",
+			factors: "[-2*c*x+c^2+s^2+x^2]",
+		},
+		{
+			// Two irreducible quadratics remain as one quartic.
+			m:       literal([][][]factor.Value{{nil, n(-1), nil, nil}, {n(1), nil, nil, nil}, {nil, nil, nil, n(2)}, {nil, nil, n(1), nil}}),
+			want:    "",
+			factors: "[-2-x^2+x^4]",
+		},
+	}
+	for i, v := range vs {
+		es, err := v.m.Eigen("x")
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+			continue
+		}
+		var got string
+		for j, e := range es.Values {
+			if j != 0 {
+				got += "; "
+			}
+			got += fmt.Sprintf("%v x%d %v", e.Value, e.Multiplicity, e.Vectors)
+		}
+		if got != v.want {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.want)
+		}
+		factors := ""
+		if len(es.Factors) != 0 {
+			factors = fmt.Sprint(es.Factors)
+		}
+		if factors != v.factors {
+			t.Errorf("[%d] got factors=%q want=%q", i, factors, v.factors)
+		}
+	}
+	if _, err := symbols(2, 2).Eigen("a"); err == nil {
+		t.Error("eigenvalue symbol in matrix accepted")
+	}
+}
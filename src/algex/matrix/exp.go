@@ -0,0 +1,43 @@
+package matrix
+
+import (
+	"fmt"
+	"math/big"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// Exp returns the matrix exponential of a square matrix, e^m, as its
+// Taylor series truncated after the m^order term. When m is nilpotent,
+// that is some power of m no higher than its dimension is zero, the
+// series is finite and Exp returns it in full with exact set to true,
+// whatever the order.
+func (m *Matrix) Exp(order int) (e *Matrix, exact bool, err error) {
+	if m.rows != m.cols {
+		return nil, false, fmt.Errorf("%w: need a square matrix, not %dx%d", ErrDimension, m.rows, m.cols)
+	}
+	if order < 0 {
+		return nil, false, fmt.Errorf("negative order %d", order)
+	}
+	p, _ := Identity(m.rows)
+	full := p
+	var truncated *Matrix
+	f := big.NewInt(1)
+	for k := 1; k <= order || k <= m.rows; k++ {
+		if k == order+1 {
+			truncated = full
+		}
+		p = p.Mx(m)
+		if p.IsZero() {
+			return full, true, nil
+		}
+		f.Mul(f, big.NewInt(int64(k)))
+		c := terms.NewExp([]factor.Value{factor.R(new(big.Rat).SetFrac(big.NewInt(1), f))})
+		full = full.Add(p, c)
+	}
+	if truncated == nil {
+		truncated = full
+	}
+	return truncated, false, nil
+}
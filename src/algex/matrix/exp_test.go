@@ -0,0 +1,66 @@
+package matrix
+
+import (
+	"testing"
+
+	"algex/factor"
+)
+
+func TestExp(t *testing.T) {
+	a := []factor.Value{factor.S("a")}
+	b := []factor.Value{factor.S("b")}
+	c := []factor.Value{factor.S("c")}
+	tt := []factor.Value{factor.S("t")}
+	mt := []factor.Value{factor.D(-1, 1), factor.S("t")}
+	vs := []struct {
+		m     *Matrix
+		order int
+		want  string
+		exact bool
+	}{
+		{
+			m:     literal([][][]factor.Value{{nil, a}, {nil, nil}}),
+			order: 0,
+			want:  "[[1, a], [0, 1]]",
+			exact: true,
+		},
+		{
+			m:     literal([][][]factor.Value{{nil, a, b}, {nil, nil, c}, {nil, nil, nil}}),
+			order: 1,
+			want:  "[[1, a, 1/2*a*c+b], [0, 1, c], [0, 0, 1]]",
+			exact: true,
+		},
+		{
+			m:     literal([][][]factor.Value{{a}}),
+			order: 3,
+			want:  "[[1+a+1/2*a^2+1/6*a^3]]",
+		},
+		{
+			// The generator of rotations about the z-axis.
+			m:     literal([][][]factor.Value{{nil, mt, nil}, {tt, nil, nil}, {nil, nil, nil}}),
+			order: 4,
+			want:  "[[1-1/2*t^2+1/24*t^4, -t+1/6*t^3, 0], [t-1/6*t^3, 1-1/2*t^2+1/24*t^4, 0], [0, 0, 1]]",
+		},
+		{
+			m:     literal([][][]factor.Value{{nil, mt, nil}, {tt, nil, nil}, {nil, nil, nil}}),
+			order: 0,
+			want:  "[[1, 0, 0], [0, 1, 0], [0, 0, 1]]",
+		},
+	}
+	for i, v := range vs {
+		e, exact, err := v.m.Exp(v.order)
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+			continue
+		}
+		if got := e.String(); got != v.want {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.want)
+		}
+		if exact != v.exact {
+			t.Errorf("[%d] got exact=%v want=%v", i, exact, v.exact)
+		}
+	}
+	if _, _, err := symbols(2, 3).Exp(2); err == nil {
+		t.Error("non-square matrix accepted")
+	}
+}
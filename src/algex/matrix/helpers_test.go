@@ -0,0 +1,67 @@
+package matrix
+
+import (
+	"algex/factor"
+	"algex/terms"
+)
+
+// exp is a shorthand for a single term expression.
+func exp(vs ...factor.Value) *terms.Exp {
+	return terms.NewExp(vs)
+}
+
+// symbols returns a rows x cols matrix of distinct symbols.
+func symbols(rows, cols int) *Matrix {
+	m, _ := NewMatrix(rows, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			m.Set(r, c, exp(factor.S(string(rune('a'+r*cols+c)))))
+		}
+	}
+	return m
+}
+
+// literal builds a matrix from rows of single term expressions. A nil
+// term leaves its element unset.
+func literal(rows [][][]factor.Value) *Matrix {
+	m, _ := NewMatrix(len(rows), len(rows[0]))
+	for r, row := range rows {
+		for c, t := range row {
+			if t != nil {
+				m.Set(r, c, terms.NewExp(t))
+			}
+		}
+	}
+	return m
+}
+
+// identity returns an n x n identity matrix.
+func identity(n int) *Matrix {
+	m, _ := Identity(n)
+	return m
+}
+
+// laurent returns an n x n matrix whose elements have negative powers.
+func laurent(n int) *Matrix {
+	m, _ := NewMatrix(n, n)
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			e := exp(factor.S(string(rune('a'+(r+2*c)%3))), factor.Sp("x", c-r))
+			if r == c {
+				e = terms.Add(e, exp(factor.Sp("y", -1)))
+			}
+			m.Set(r, c, e)
+		}
+	}
+	return m
+}
+
+// singular returns laurent(n) with its last row replaced by a multiple
+// of its first, so that it has rank n-1.
+func singular(n int) *Matrix {
+	m := laurent(n)
+	for c := 0; c < n; c++ {
+		m.Set(n-1, c, terms.Mul(m.el(0, c), exp(factor.Sp("x", -1))))
+	}
+	return m
+}
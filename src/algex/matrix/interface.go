@@ -0,0 +1,211 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// ErrDimension indicates that the dimensions of a matrix are invalid, or
+// do not suit an operation.
+var ErrDimension = errors.New("bad matrix dimensions")
+
+// ErrBounds indicates that a row or column index is outside a matrix.
+var ErrBounds = errors.New("index out of range")
+
+// Interface is implemented by the matrix types of this package: the
+// dense Matrix, Sparse and Blocks. The package functions Mul, Sum,
+// Substitute and Transpose operate on any of them.
+type Interface interface {
+	// Dims returns the number of rows and columns.
+	Dims() (rows, cols int)
+	// El returns an element, which may be nil if it is zero.
+	El(row, col int) (*terms.Exp, error)
+	// Set sets an element.
+	Set(row, col int, e *terms.Exp) error
+	// String serializes the matrix in the form [[a, b], [c, d]].
+	String() string
+}
+
+var (
+	_ Interface = (*Matrix)(nil)
+	_ Interface = (*Sparse)(nil)
+	_ Interface = (*Blocks)(nil)
+)
+
+// format serializes any matrix in the form of Matrix.String.
+func format(m Interface) string {
+	rows, cols := m.Dims()
+	var rs []string
+	for r := 0; r < rows; r++ {
+		var cs []string
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			cs = append(cs, e.String())
+		}
+		rs = append(rs, "["+strings.Join(cs, ", ")+"]")
+	}
+	return "[" + strings.Join(rs, ", ") + "]"
+}
+
+// Copy returns a dense copy of any matrix.
+func Copy(m Interface) *Matrix {
+	rows, cols := m.Dims()
+	d, _ := NewMatrix(rows, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			d.Set(r, c, e)
+		}
+	}
+	return d
+}
+
+// Equal indicates that two matrices have the same dimensions and equal
+// elements. Nil elements equal zero.
+func Equal(a, b Interface) bool {
+	rows, cols := a.Dims()
+	if r, c := b.Dims(); r != rows || c != cols {
+		return false
+	}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			x, _ := a.El(r, c)
+			y, _ := b.El(r, c)
+			if x.String() != y.String() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// dense returns m itself if it is a Matrix, or a dense copy of it.
+func dense(m Interface) *Matrix {
+	if d, ok := m.(*Matrix); ok {
+		return d
+	}
+	return Copy(m)
+}
+
+// Mul multiplies a x b for any two matrices. The product of two Sparse
+// matrices is Sparse, and otherwise it is a dense Matrix.
+func Mul(a, b Interface) (Interface, error) {
+	if x, ok := a.(*Sparse); ok {
+		if y, ok := b.(*Sparse); ok {
+			return x.Mul(y)
+		}
+	}
+	return dense(a).Mul(dense(b))
+}
+
+// Sum adds b, multiplied by scale, to a for any two matrices. The sum of
+// two Sparse matrices is Sparse, and otherwise it is a dense Matrix.
+func Sum(a, b Interface, scale *terms.Exp) (Interface, error) {
+	if x, ok := a.(*Sparse); ok {
+		if y, ok := b.(*Sparse); ok {
+			return x.Sum(y, scale)
+		}
+	}
+	return dense(a).Sum(dense(b), scale)
+}
+
+// Substitute performs a substitution on all elements of any matrix. A
+// Sparse matrix remains Sparse, and others become a dense Matrix.
+func Substitute(m Interface, b []factor.Value, s *terms.Exp) (Interface, error) {
+	if x, ok := m.(*Sparse); ok {
+		return x.Substitute(b, s)
+	}
+	return dense(m).Substitute(b, s)
+}
+
+// Transpose returns the transpose of any matrix. A Sparse matrix remains
+// Sparse, and others become a dense Matrix.
+func Transpose(m Interface) Interface {
+	if x, ok := m.(*Sparse); ok {
+		return x.Transpose()
+	}
+	return dense(m).Transpose()
+}
+
+// Blocks is a matrix formed from a grid of other matrices. Unlike Block,
+// it refers to the blocks rather than copying them, so setting an
+// element of a Blocks sets the element of the underlying block.
+type Blocks struct {
+	grid [][]Interface
+	// rowAt and colAt hold the first row and column of each block
+	// row and column, followed by the dimensions of the whole.
+	rowAt, colAt []int
+}
+
+// NewBlocks creates a matrix from a grid of blocks. The blocks of a row
+// must have the same number of rows, and those of a column the same
+// number of columns.
+func NewBlocks(grid [][]Interface) (*Blocks, error) {
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return nil, fmt.Errorf("%w: no blocks", ErrDimension)
+	}
+	b := &Blocks{grid: grid, rowAt: []int{0}, colAt: []int{0}}
+	for j, x := range grid[0] {
+		_, cols := x.Dims()
+		b.colAt = append(b.colAt, b.colAt[j]+cols)
+	}
+	for i, br := range grid {
+		if len(br) != len(grid[0]) {
+			return nil, fmt.Errorf("%w: block row %d has %d blocks, not %d", ErrDimension, i, len(br), len(grid[0]))
+		}
+		rows, _ := br[0].Dims()
+		for j, x := range br {
+			r, c := x.Dims()
+			if r != rows || c != b.colAt[j+1]-b.colAt[j] {
+				return nil, fmt.Errorf("%w: block [%d,%d] is %dx%d, not %dx%d", ErrDimension, i, j, r, c, rows, b.colAt[j+1]-b.colAt[j])
+			}
+		}
+		b.rowAt = append(b.rowAt, b.rowAt[i]+rows)
+	}
+	return b, nil
+}
+
+// Dims returns the number of rows and columns of a matrix.
+func (b *Blocks) Dims() (rows, cols int) {
+	return b.rowAt[len(b.rowAt)-1], b.colAt[len(b.colAt)-1]
+}
+
+// find returns the block holding an element and the indices of the
+// element within it.
+func (b *Blocks) find(row, col int) (Interface, int, int, error) {
+	rows, cols := b.Dims()
+	if row < 0 || col < 0 || row >= rows || col >= cols {
+		return nil, 0, 0, fmt.Errorf("%w: [%d,%d] in %dx%d matrix", ErrBounds, row, col, rows, cols)
+	}
+	i := sort.SearchInts(b.rowAt, row+1) - 1
+	j := sort.SearchInts(b.colAt, col+1) - 1
+	return b.grid[i][j], row - b.rowAt[i], col - b.colAt[j], nil
+}
+
+// El returns the row,col element of the matrix.
+func (b *Blocks) El(row, col int) (*terms.Exp, error) {
+	x, r, c, err := b.find(row, col)
+	if err != nil {
+		return nil, err
+	}
+	return x.El(r, c)
+}
+
+// Set sets the value of a matrix element in its block.
+func (b *Blocks) Set(row, col int, e *terms.Exp) error {
+	x, r, c, err := b.find(row, col)
+	if err != nil {
+		return err
+	}
+	return x.Set(r, c, e)
+}
+
+// String serializes a matrix for displaying.
+func (b *Blocks) String() string {
+	return format(b)
+}
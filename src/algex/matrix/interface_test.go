@@ -0,0 +1,153 @@
+package matrix
+
+import (
+	"errors"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestErrors(t *testing.T) {
+	m, s := symbols(2, 3), symbols(2, 3).Sparse()
+	for i, x := range []Interface{m, s} {
+		if e, err := x.El(1, 2); err != nil || e.String() != "f" {
+			t.Errorf("[%d] got El(1,2)=%v (%v), want f", i, e, err)
+		}
+		for _, rc := range [][2]int{{-1, 0}, {2, 0}, {0, 3}, {1, -1}} {
+			if _, err := x.El(rc[0], rc[1]); !errors.Is(err, ErrBounds) {
+				t.Errorf("[%d] El(%d,%d) got %v, want ErrBounds", i, rc[0], rc[1], err)
+			}
+			if err := x.Set(rc[0], rc[1], nil); !errors.Is(err, ErrBounds) {
+				t.Errorf("[%d] Set(%d,%d) got %v, want ErrBounds", i, rc[0], rc[1], err)
+			}
+		}
+	}
+
+	_, errNew := NewMatrix(0, 2)
+	_, errSparse := NewSparse(2, 0)
+	_, errMul := m.Mul(m)
+	_, errSum := m.Sum(symbols(3, 2), nil)
+	_, errDet := m.Det()
+	_, errPow := m.Pow(2)
+	_, errSparseMul := s.Mul(s)
+	_, errSolve := Solve(m, symbols(3, 1))
+	_, errCross := symbols(2, 1).Cross(symbols(3, 1))
+	_, errBlocks := NewBlocks([][]Interface{{m, symbols(3, 1)}})
+	for i, err := range []error{errNew, errSparse, errMul, errSum, errDet, errPow, errSparseMul, errSolve, errCross, errBlocks} {
+		if !errors.Is(err, ErrDimension) {
+			t.Errorf("[%d] got %v, want ErrDimension", i, err)
+		}
+	}
+	if _, err := m.Submatrix(Range{0, 3}, Range{0, 1}); !errors.Is(err, ErrBounds) {
+		t.Errorf("got %v, want ErrBounds", err)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	a, b := symbols(2, 2), symbols(2, 1).Sparse()
+	id := identity(3)
+	c, _ := id.Submatrix(Range{0, 1}, Range{0, 3})
+	x, err := NewBlocks([][]Interface{{a, b}, {c}})
+	if err == nil {
+		t.Fatalf("ragged grid accepted: %v", x)
+	}
+	x, err = NewBlocks([][]Interface{{a, b}, {symbols(1, 2), symbols(1, 1)}})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if rows, cols := x.Dims(); rows != 3 || cols != 3 {
+		t.Errorf("got %dx%d, want 3x3", rows, cols)
+	}
+	if got, want := x.String(), "[[a, b, a], [c, d, b], [a, b, a]]"; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	// Setting an element of the view sets the underlying block.
+	if err := x.Set(1, 2, terms.NewExp([]factor.Value{factor.S("z")})); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if e, _ := b.El(1, 0); e.String() != "z" {
+		t.Errorf("got block element %v, want z", e)
+	}
+	if _, err := x.El(3, 0); !errors.Is(err, ErrBounds) {
+		t.Errorf("got %v, want ErrBounds", err)
+	}
+	d := Copy(x)
+	if !Equal(d, x) {
+		t.Errorf("copy %v differs from %v", d, x)
+	}
+	d.Set(0, 0, nil)
+	if Equal(d, x) {
+		t.Errorf("%v equals %v", d, x)
+	}
+	if Equal(a, b) {
+		t.Error("matrices of different dimensions are equal")
+	}
+}
+
+func TestOperations(t *testing.T) {
+	m := symbols(2, 2)
+	s := m.Sparse()
+	b, _ := NewBlocks([][]Interface{{symbols(2, 1), symbols(2, 1).Sparse()}})
+	two := terms.NewExp([]factor.Value{factor.D(2, 1)})
+	for i, v := range []struct {
+		a, b   Interface
+		sparse bool
+	}{
+		{a: m, b: m},
+		{a: s, b: s, sparse: true},
+		{a: m, b: s},
+		{a: s, b: m},
+		{a: b, b: s},
+	} {
+		p, err := Mul(v.a, v.b)
+		if err != nil {
+			t.Fatalf("[%d] mul failed: %v", i, err)
+		}
+		if want := Copy(v.a).Mx(Copy(v.b)); !Equal(p, want) {
+			t.Errorf("[%d] got product %v, want %v", i, p, want)
+		}
+		q, err := Sum(v.a, v.b, two)
+		if err != nil {
+			t.Fatalf("[%d] sum failed: %v", i, err)
+		}
+		if want := Copy(v.a).Add(Copy(v.b), two); !Equal(q, want) {
+			t.Errorf("[%d] got sum %v, want %v", i, q, want)
+		}
+		if _, ok := p.(*Sparse); ok != v.sparse {
+			t.Errorf("[%d] got product %T, sparse=%v", i, p, v.sparse)
+		}
+		if _, ok := q.(*Sparse); ok != v.sparse {
+			t.Errorf("[%d] got sum %T, sparse=%v", i, q, v.sparse)
+		}
+	}
+	if _, err := Mul(s, symbols(3, 1).Sparse()); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want ErrDimension", err)
+	}
+	if _, err := Sum(m, symbols(2, 1), nil); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want ErrDimension", err)
+	}
+
+	a := []factor.Value{factor.S("a")}
+	for i, x := range []Interface{symbols(2, 3), symbols(2, 3).Sparse(), b} {
+		tr := Transpose(x)
+		if want := Copy(x).Transpose(); !Equal(tr, want) {
+			t.Errorf("[%d] got transpose %v, want %v", i, tr, want)
+		}
+		y, err := Substitute(x, a, two)
+		if err != nil {
+			t.Fatalf("[%d] substitute failed: %v", i, err)
+		}
+		want, _ := Copy(x).Substitute(a, two)
+		if !Equal(y, want) {
+			t.Errorf("[%d] got %v, want %v", i, y, want)
+		}
+		_, sparse := x.(*Sparse)
+		if _, ok := y.(*Sparse); ok != sparse {
+			t.Errorf("[%d] got %T from %T", i, y, x)
+		}
+		if _, err := Substitute(x, a, terms.NewExp(a, []factor.Value{factor.S("b")})); !errors.Is(err, terms.ErrNonTerminating) {
+			t.Errorf("[%d] a -> a+b got %v, want ErrNonTerminating", i, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// ErrSingular indicates that a matrix has no inverse.
+var ErrSingular = errors.New("singular matrix")
+
+// Adjugate returns the adjugate of a square matrix: the transpose of
+// its matrix of cofactors.
+func (m *Matrix) Adjugate() (*Matrix, error) {
+	if m.rows != m.cols {
+		return nil, fmt.Errorf("%w: need a square matrix, not %dx%d", ErrDimension, m.rows, m.cols)
+	}
+	a, _ := NewMatrix(m.rows, m.cols)
+	if m.rows == 1 {
+		a.Set(0, 0, terms.NewExp([]factor.Value{factor.D(1, 1)}))
+		return a, nil
+	}
+	for r := 0; r < m.rows; r++ {
+		for c := 0; c < m.cols; c++ {
+			n, err := m.Minor(r, c)
+			if err != nil {
+				return nil, err
+			}
+			d, err := n.Det()
+			if err != nil {
+				return nil, err
+			}
+			if (r+c)%2 == 1 {
+				d = terms.Sub(terms.NewExp(), d)
+			}
+			a.Set(c, r, d)
+		}
+	}
+	return a, nil
+}
+
+// Inverse returns the inverse of a square matrix as num/den. When each
+// element of the adjugate can be divided exactly by the determinant, as
+// is always the case for a single term determinant like 1 or ct^2, num
+// is the inverse and den is nil. Otherwise, num is the adjugate and den
+// is the determinant. An error wrapping ErrSingular is returned if the
+// determinant is zero.
+func (m *Matrix) Inverse() (num *Matrix, den *terms.Exp, err error) {
+	d, err := m.Det()
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.IsZero() {
+		return nil, nil, fmt.Errorf("%w: zero determinant", ErrSingular)
+	}
+	a, err := m.Adjugate()
+	if err != nil {
+		return nil, nil, err
+	}
+	inv, _ := NewMatrix(a.rows, a.cols)
+	for i, e := range a.data {
+		if e.IsZero() {
+			continue
+		}
+		q, err := terms.Quo(e, d)
+		if errors.Is(err, terms.ErrNotDivisible) {
+			return a, d, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		inv.data[i] = q
+	}
+	return inv, nil, nil
+}
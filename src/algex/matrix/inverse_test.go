@@ -0,0 +1,93 @@
+package matrix
+
+import (
+	"errors"
+	"testing"
+
+	"algex/factor"
+)
+
+func TestInverse(t *testing.T) {
+	one := []factor.Value{factor.D(1, 1)}
+	two := []factor.Value{factor.D(2, 1)}
+	a, b, c, d, x := []factor.Value{factor.S("a")}, []factor.Value{factor.S("b")}, []factor.Value{factor.S("c")}, []factor.Value{factor.S("d")}, []factor.Value{factor.S("x")}
+	vs := []struct {
+		m        *Matrix
+		num, den string
+	}{
+		{
+			m:   literal([][][]factor.Value{{one, a}, {nil, one}}),
+			num: "[[1, -a], [0, 1]]",
+		},
+		{
+			m:   literal([][][]factor.Value{{two, x}, {nil, x}}),
+			num: "[[1/2, -1/2], [0, x^-1]]",
+		},
+		{
+			m:   literal([][][]factor.Value{{a, b}, {c, d}}),
+			num: "[[d, -b], [-c, a]]",
+			den: "a*d-b*c",
+		},
+		{
+			m:   literal([][][]factor.Value{{x}}),
+			num: "[[x^-1]]",
+		},
+	}
+	for i, v := range vs {
+		num, den, err := v.m.Inverse()
+		if err != nil {
+			t.Errorf("[%d] inverse of %v failed: %v", i, v.m, err)
+			continue
+		}
+		if got := num.String(); got != v.num {
+			t.Errorf("[%d] inverse of %v got num=%q want=%q", i, v.m, got, v.num)
+		}
+		if v.den == "" {
+			if den != nil {
+				t.Errorf("[%d] inverse of %v got den=%q want=nil", i, v.m, den)
+			}
+			if p := v.m.Mx(num); p.String() != identity(p.rows).String() {
+				t.Errorf("[%d] %v * %v = %v", i, v.m, num, p)
+			}
+		} else if den == nil || den.String() != v.den {
+			t.Errorf("[%d] inverse of %v got den=%v want=%q", i, v.m, den, v.den)
+		}
+	}
+
+	s := literal([][][]factor.Value{{a, a}, {a, a}})
+	if _, _, err := s.Inverse(); !errors.Is(err, ErrSingular) {
+		t.Errorf("singular inverse got err=%v", err)
+	}
+	if _, _, err := symbols(2, 3).Inverse(); err == nil {
+		t.Error("non-square inverse succeeded")
+	}
+}
+
+func TestLaurentInverse(t *testing.T) {
+	// A unit triangular matrix has determinant 1, so its inverse has
+	// no denominator and keeps the negative powers.
+	u := identity(3)
+	u.Set(0, 1, exp(factor.Sp("x", -1)))
+	u.Set(1, 2, exp(factor.Sp("x", -1)))
+	num, den, err := u.Inverse()
+	if err != nil {
+		t.Fatalf("triangular inverse failed: %v", err)
+	}
+	if den != nil {
+		t.Errorf("triangular inverse got den=%v, want nil", den)
+	}
+	if got, want := num.String(), "[[1, -x^-1, x^-2], [0, 1, -x^-1], [0, 0, 1]]"; got != want {
+		t.Errorf("triangular inverse got=%q want=%q", got, want)
+	}
+
+	m := laurent(3)
+	if num, den, err = m.Inverse(); err != nil {
+		t.Fatalf("inverse failed: %v", err)
+	}
+	if p := m.Mx(num); !Equal(p, identity(3).Scale(den)) {
+		t.Errorf("%v * %v = %v, want %v", m, num, p, den)
+	}
+	if _, _, err := singular(4).Inverse(); !errors.Is(err, ErrSingular) {
+		t.Errorf("singular inverse got err=%v, want ErrSingular", err)
+	}
+}
@@ -0,0 +1,41 @@
+package matrix
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"algex/terms"
+)
+
+// MarshalJSON encodes a matrix as a JSON array of rows. Each row is an
+// array of expressions, with null for any unset element.
+func (m *Matrix) MarshalJSON() ([]byte, error) {
+	rows := make([][]*terms.Exp, m.rows)
+	for r := range rows {
+		rows[r] = m.data[r*m.cols : (r+1)*m.cols]
+	}
+	return json.Marshal(rows)
+}
+
+// UnmarshalJSON decodes a matrix encoded by MarshalJSON.
+func (m *Matrix) UnmarshalJSON(data []byte) error {
+	var rows [][]*terms.Exp
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("%w: empty matrix", ErrDimension)
+	}
+	n, err := NewMatrix(len(rows), len(rows[0]))
+	if err != nil {
+		return err
+	}
+	for r, row := range rows {
+		if len(row) != n.cols {
+			return fmt.Errorf("%w: row %d has %d columns, not %d", ErrDimension, r, len(row), n.cols)
+		}
+		copy(n.data[r*n.cols:], row)
+	}
+	*m = *n
+	return nil
+}
@@ -0,0 +1,37 @@
+package matrix
+
+import (
+	"encoding/json"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestJSON(t *testing.T) {
+	m, _ := NewMatrix(2, 2)
+	m.Set(0, 1, terms.NewExp([]factor.Value{factor.D(1, 2), factor.S("x")}))
+	m.Set(1, 0, terms.NewExp())
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("failed to marshal %v: %v", m, err)
+	}
+	if got, want := string(b), `[[null,[{"coeff":"1/2","factors":[{"sym":"x","pow":1}]}]],[[],null]]`; got != want {
+		t.Errorf("got=%s want=%s", got, want)
+	}
+	n := &Matrix{}
+	if err := json.Unmarshal(b, n); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", b, err)
+	}
+	if got, want := n.String(), m.String(); got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	if n.el(0, 0) != nil {
+		t.Errorf("null element decoded as %q", n.el(0, 0))
+	}
+	for i, j := range []string{`[]`, `[[[]],[[],[]]]`, `{}`} {
+		if err := json.Unmarshal([]byte(j), n); err == nil {
+			t.Errorf("[%d] %s unmarshaled as %v", i, j, n)
+		}
+	}
+}
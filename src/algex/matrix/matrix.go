@@ -1,4 +1,10 @@
 // Package matrix manages matrices of expressions.
+//
+// Operations that can fail return an error. Errors caused by the
+// dimensions of a matrix wrap ErrDimension, and errors caused by an
+// index outside a matrix wrap ErrBounds, so both can be detected with
+// errors.Is. The panicking variants, such as Mx and Add, are for use
+// when the dimensions are known to be compatible.
 package matrix
 
 import (
@@ -19,7 +25,7 @@ type Matrix struct {
 // NewMatrix creates a rows x cols matrix.
 func NewMatrix(rows, cols int) (*Matrix, error) {
 	if rows <= 0 || cols <= 0 {
-		return nil, fmt.Errorf("need positive dimensions, not %dx%d", rows, cols)
+		return nil, fmt.Errorf("%w: need positive dimensions, not %dx%d", ErrDimension, rows, cols)
 	}
 	m := &Matrix{
 		rows: rows,
@@ -29,6 +35,11 @@ func NewMatrix(rows, cols int) (*Matrix, error) {
 	return m, nil
 }
 
+// Dims returns the number of rows and columns of a matrix.
+func (m *Matrix) Dims() (rows, cols int) {
+	return m.rows, m.cols
+}
+
 // String serializes a matrix for displaying.
 func (m *Matrix) String() string {
 	var rs []string
@@ -45,21 +56,30 @@ func (m *Matrix) String() string {
 // Set sets the value of a matrix element.
 func (m *Matrix) Set(row, col int, e *terms.Exp) error {
 	if row < 0 || col < 0 || row >= m.rows || col >= m.cols {
-		return fmt.Errorf("bad cell: [%d,%d] in %dx%d matrix", row, col, m.rows, m.cols)
+		return fmt.Errorf("%w: [%d,%d] in %dx%d matrix", ErrBounds, row, col, m.rows, m.cols)
 	}
 	m.data[col+m.cols*row] = e
 	return nil
 }
 
-// El returns the row,col element of the matrix.
-func (m *Matrix) El(row, col int) *terms.Exp {
+// El returns the row,col element of the matrix. A nil element is zero.
+func (m *Matrix) El(row, col int) (*terms.Exp, error) {
+	if row < 0 || col < 0 || row >= m.rows || col >= m.cols {
+		return nil, fmt.Errorf("%w: [%d,%d] in %dx%d matrix", ErrBounds, row, col, m.rows, m.cols)
+	}
+	return m.el(row, col), nil
+}
+
+// el returns the row,col element of the matrix without checking the
+// indices.
+func (m *Matrix) el(row, col int) *terms.Exp {
 	return m.data[col+m.cols*row]
 }
 
 // Identity returns a square identity matrix of dimension n.
 func Identity(n int) (*Matrix, error) {
 	if n <= 0 {
-		return nil, fmt.Errorf("invalid identity matrix of dimension n=%d", n)
+		return nil, fmt.Errorf("%w: identity matrix of dimension n=%d", ErrDimension, n)
 	}
 	m, _ := NewMatrix(n, n)
 	for i := 0; i < n; i++ {
@@ -71,7 +91,7 @@ func Identity(n int) (*Matrix, error) {
 // Mul multiplies m x n with conventional matrix multiplication.
 func (m *Matrix) Mul(n *Matrix) (*Matrix, error) {
 	if m.cols != n.rows {
-		return nil, fmt.Errorf("a cols(%d) != b rows(%d)", m.cols, n.rows)
+		return nil, fmt.Errorf("%w: a cols(%d) != b rows(%d)", ErrDimension, m.cols, n.rows)
 	}
 	a, err := NewMatrix(m.rows, n.cols)
 	if err != nil {
@@ -81,7 +101,7 @@ func (m *Matrix) Mul(n *Matrix) (*Matrix, error) {
 		for c := 0; c < a.cols; c++ {
 			var e []*terms.Exp
 			for i := 0; i < m.cols; i++ {
-				x, y := m.El(r, i), n.El(i, c)
+				x, y := m.el(r, i), n.el(i, c)
 				if x != nil && y != nil {
 					e = append(e, terms.Mul(x, y))
 				}
@@ -104,14 +124,14 @@ func (m *Matrix) Mx(n *Matrix) *Matrix {
 // Sum adds two matrices.
 func (m *Matrix) Sum(n *Matrix, scale *terms.Exp) (*Matrix, error) {
 	if m.rows != n.rows || m.cols != n.cols {
-		return nil, fmt.Errorf("inequivalent dimensions %dx%d != %dx%d", m.rows, m.cols, n.rows, n.cols)
+		return nil, fmt.Errorf("%w: inequivalent dimensions %dx%d != %dx%d", ErrDimension, m.rows, m.cols, n.rows, n.cols)
 	}
 	a, _ := NewMatrix(m.rows, m.cols)
 	for r := 0; r < m.rows; r++ {
 		for c := 0; c < m.cols; c++ {
-			if q := n.El(r, c); q == nil {
-				a.Set(r, c, m.El(r, c))
-			} else if p := m.El(r, c); p == nil {
+			if q := n.el(r, c); q == nil {
+				a.Set(r, c, m.el(r, c))
+			} else if p := m.el(r, c); p == nil {
 				a.Set(r, c, terms.Mul(q, scale))
 			} else {
 				a.Set(r, c, terms.Add(p, terms.Mul(q, scale)))
@@ -130,12 +150,33 @@ func (m *Matrix) Add(n *Matrix, scale *terms.Exp) *Matrix {
 	return a
 }
 
-// Performs a substitution on all elements of a matrix.
-func (m *Matrix) Substitute(b []factor.Value, s *terms.Exp) *Matrix {
+// Substitute performs a substitution on all elements of a matrix. It
+// fails as terms.Substitute does for any element.
+func (m *Matrix) Substitute(b []factor.Value, s *terms.Exp) (*Matrix, error) {
+	n, _ := NewMatrix(m.rows, m.cols)
+	for r := 0; r < m.rows; r++ {
+		for c := 0; c < m.cols; c++ {
+			if e := m.el(r, c); e != nil {
+				x, err := terms.Substitute(e, b, s)
+				if err != nil {
+					return nil, fmt.Errorf("element (%d,%d): %w", r, c, err)
+				}
+				n.Set(r, c, x)
+			}
+		}
+	}
+	return n, nil
+}
+
+// SubstituteOnce performs a single pass substitution, as
+// terms.SubstituteOnce, on all elements of a matrix.
+func (m *Matrix) SubstituteOnce(b []factor.Value, s *terms.Exp) *Matrix {
 	n, _ := NewMatrix(m.rows, m.cols)
 	for r := 0; r < m.rows; r++ {
 		for c := 0; c < m.cols; c++ {
-			n.Set(r, c, terms.Substitute(m.El(r, c), b, s))
+			if e := m.el(r, c); e != nil {
+				n.Set(r, c, terms.SubstituteOnce(e, b, s))
+			}
 		}
 	}
 	return n
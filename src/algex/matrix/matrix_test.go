@@ -1,6 +1,7 @@
 package matrix
 
 import (
+	"errors"
 	"testing"
 
 	"algex/factor"
@@ -26,7 +27,7 @@ func TestMul(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to make 2x2 identity: %v", err)
 	}
-	b.Set(0, 1, terms.Mul(a.El(0, 0), terms.NewExp([]factor.Value{factor.Sp("x", 2)})))
+	b.Set(0, 1, terms.Mul(a.el(0, 0), terms.NewExp([]factor.Value{factor.Sp("x", 2)})))
 
 	c, err := a.Mul(b)
 	if err != nil {
@@ -62,3 +63,21 @@ func TestSum(t *testing.T) {
 		t.Errorf("add: got=%q, want=%q", got, want)
 	}
 }
+
+func TestSubstituteOnce(t *testing.T) {
+	a := terms.NewExp([]factor.Value{factor.S("a")})
+	m, _ := NewMatrix(1, 2)
+	m.Set(0, 0, a)
+	m.Set(0, 1, terms.Mul(a, a))
+	s := terms.NewExp([]factor.Value{factor.S("a")}, []factor.Value{factor.S("b")})
+	if got, want := m.SubstituteOnce([]factor.Value{factor.S("a")}, s).String(), "[[a+b, 2*a*b+a^2+b^2]]"; got != want {
+		t.Errorf("substitute once: got=%q, want=%q", got, want)
+	}
+	if x, err := m.Substitute([]factor.Value{factor.S("a")}, s); !errors.Is(err, terms.ErrNonTerminating) {
+		t.Errorf("non-terminating substitute: got=%v (%v), want %v", x, err, terms.ErrNonTerminating)
+	}
+	x, err := m.Substitute([]factor.Value{factor.S("a")}, terms.NewExp([]factor.Value{factor.S("b")}))
+	if err != nil || x.String() != "[[b, b^2]]" {
+		t.Errorf("substitute: got=%v (%v), want [[b, b^2]]", x, err)
+	}
+}
@@ -0,0 +1,109 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// Parallel computes the elements of matrix operations concurrently with
+// a pool of worker goroutines. Each element is computed exactly as the
+// sequential operation computes it, so the results are identical. Like
+// MulCtx and SubstituteCtx, the operations fail if their context is
+// done or its terms.Limits are exceeded.
+type Parallel struct {
+	// Workers is the number of goroutines to use. Zero or less means
+	// runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+// each calls f for every index 0..n-1 on the workers of p. It stops at
+// the first error returned by f, or when ctx is done, and returns that
+// error. The context passed to f is canceled when any call fails.
+func (p Parallel) each(ctx context.Context, n int, f func(ctx context.Context, i int) error) error {
+	w := p.Workers
+	if w <= 0 {
+		w = runtime.GOMAXPROCS(0)
+	}
+	if w > n {
+		w = n
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var err error
+	for k := 0; k < w; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if e := f(ctx, i); e != nil {
+					once.Do(func() {
+						err = e
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	once.Do(func() {
+		err = ctx.Err()
+	})
+	return err
+}
+
+// Mul multiplies m x n with conventional matrix multiplication,
+// computing the elements concurrently.
+func (p Parallel) Mul(ctx context.Context, m, n *Matrix) (*Matrix, error) {
+	if m.cols != n.rows {
+		return nil, fmt.Errorf("%w: a cols(%d) != b rows(%d)", ErrDimension, m.cols, n.rows)
+	}
+	a, err := NewMatrix(m.rows, n.cols)
+	if err != nil {
+		return nil, err
+	}
+	err = p.each(ctx, len(a.data), func(ctx context.Context, k int) error {
+		e, err := mulEl(ctx, m, n, k/a.cols, k%a.cols)
+		a.data[k] = e
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Substitute performs a substitution on all elements of a matrix,
+// computing the elements concurrently.
+func (p Parallel) Substitute(ctx context.Context, m *Matrix, b []factor.Value, s *terms.Exp) (*Matrix, error) {
+	n, _ := NewMatrix(m.rows, m.cols)
+	err := p.each(ctx, len(m.data), func(ctx context.Context, k int) error {
+		e := m.data[k]
+		if e == nil {
+			return nil
+		}
+		x, err := terms.SubstituteCtx(ctx, e, b, s)
+		n.data[k] = x
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,72 @@
+package matrix
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestParallel(t *testing.T) {
+	m := symbols(6, 6)
+	want := m.Mx(m).Mx(m)
+	b := []factor.Value{factor.S("a")}
+	s := terms.NewExp([]factor.Value{factor.S("x")}, []factor.Value{factor.D(1, 1)})
+	for _, w := range []int{0, 1, 3, 100} {
+		p := Parallel{Workers: w}
+		ctx := context.Background()
+		x, err := p.Mul(ctx, m, m)
+		if err == nil {
+			x, err = p.Mul(ctx, x, m)
+		}
+		if err != nil {
+			t.Errorf("[%d] mul failed: %v", w, err)
+			continue
+		}
+		if x.String() != want.String() {
+			t.Errorf("[%d] parallel product differs", w)
+		}
+		y, err := p.Substitute(ctx, x, b, s)
+		if err != nil {
+			t.Errorf("[%d] substitute failed: %v", w, err)
+			continue
+		}
+		if z, _ := want.Substitute(b, s); y.String() != z.String() {
+			t.Errorf("[%d] parallel substitution differs", w)
+		}
+	}
+}
+
+func TestParallelErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := symbols(3, 3)
+	p := Parallel{Workers: 2}
+	if _, err := p.Mul(ctx, m, m); !errors.Is(err, context.Canceled) {
+		t.Errorf("mul got %v, want context.Canceled", err)
+	}
+	if _, err := p.Substitute(ctx, m, []factor.Value{factor.S("a")}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("substitute got %v, want context.Canceled", err)
+	}
+	if _, err := p.Mul(context.Background(), m, symbols(2, 2)); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want ErrDimension", err)
+	}
+}
+
+func TestParallelLimits(t *testing.T) {
+	m := symbols(4, 4)
+	ctx := terms.WithLimits(context.Background(), terms.Limits{MaxTerms: 10})
+	p := Parallel{Workers: 3}
+	x, err := p.Mul(ctx, m, m)
+	if err != nil {
+		t.Fatalf("m*m failed: %v", err)
+	}
+	if _, err := p.Mul(ctx, x, x); !errors.Is(err, terms.ErrLimit) {
+		t.Errorf("got %v, want ErrLimit", err)
+	}
+	if _, err := x.MulCtx(ctx, x); !errors.Is(err, terms.ErrLimit) {
+		t.Errorf("MulCtx got %v, want ErrLimit", err)
+	}
+}
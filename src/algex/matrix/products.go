@@ -0,0 +1,76 @@
+package matrix
+
+import (
+	"fmt"
+
+	"algex/terms"
+)
+
+// Scale returns m with every element multiplied by e.
+func (m *Matrix) Scale(e *terms.Exp) *Matrix {
+	a, _ := NewMatrix(m.rows, m.cols)
+	for i, x := range m.data {
+		if x != nil {
+			a.data[i] = terms.Mul(x, e)
+		}
+	}
+	return a
+}
+
+// Hadamard returns the elementwise product of two matrices of the same
+// dimensions.
+func (m *Matrix) Hadamard(n *Matrix) (*Matrix, error) {
+	if m.rows != n.rows || m.cols != n.cols {
+		return nil, fmt.Errorf("%w: inequivalent dimensions %dx%d != %dx%d", ErrDimension, m.rows, m.cols, n.rows, n.cols)
+	}
+	a, _ := NewMatrix(m.rows, m.cols)
+	for i, x := range m.data {
+		if y := n.data[i]; x != nil && y != nil {
+			a.data[i] = terms.Mul(x, y)
+		}
+	}
+	return a, nil
+}
+
+// Kron returns the Kronecker product of m and n: the block matrix whose
+// (i, j) block is n scaled by the (i, j) element of m.
+func (m *Matrix) Kron(n *Matrix) *Matrix {
+	a, _ := NewMatrix(m.rows*n.rows, m.cols*n.cols)
+	for r := 0; r < m.rows; r++ {
+		for c := 0; c < m.cols; c++ {
+			x := m.el(r, c)
+			if x == nil {
+				continue
+			}
+			for i := 0; i < n.rows; i++ {
+				for j := 0; j < n.cols; j++ {
+					if y := n.el(i, j); y != nil {
+						a.Set(r*n.rows+i, c*n.cols+j, terms.Mul(x, y))
+					}
+				}
+			}
+		}
+	}
+	return a
+}
+
+// Pow returns the n-th power of a square matrix, computed by repeated
+// squaring. The zeroth power is the identity matrix.
+func (m *Matrix) Pow(n int) (*Matrix, error) {
+	if m.rows != m.cols {
+		return nil, fmt.Errorf("%w: need a square matrix, not %dx%d", ErrDimension, m.rows, m.cols)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("negative power %d; use Inverse", n)
+	}
+	a, _ := Identity(m.rows)
+	for x := m; n != 0; n >>= 1 {
+		if n&1 != 0 {
+			a = a.Mx(x)
+		}
+		if n > 1 {
+			x = x.Mx(x)
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,81 @@
+package matrix
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestScale(t *testing.T) {
+	m := symbols(2, 2)
+	m.Set(1, 0, nil)
+	got := m.Scale(terms.NewExp([]factor.Value{factor.D(2, 1), factor.S("x")}))
+	if want := "[[2*a*x, 2*b*x], [0, 2*d*x]]"; got.String() != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+}
+
+func TestHadamard(t *testing.T) {
+	m := symbols(2, 2)
+	got, err := m.Hadamard(m)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if want := "[[a^2, b^2], [c^2, d^2]]"; got.String() != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	if _, err := m.Hadamard(symbols(2, 3)); err == nil {
+		t.Error("mismatched dimensions accepted")
+	}
+}
+
+func TestKron(t *testing.T) {
+	vs := []struct {
+		m, n *Matrix
+		want string
+	}{
+		{identity(2), symbols(1, 2), "[[a, b, 0, 0], [0, 0, a, b]]"},
+		{symbols(2, 1), symbols(1, 2), "[[a^2, a*b], [a*b, b^2]]"},
+	}
+	for i, v := range vs {
+		if got := v.m.Kron(v.n).String(); got != v.want {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.want)
+		}
+	}
+}
+
+func TestPow(t *testing.T) {
+	one := []factor.Value{factor.D(1, 1)}
+	j := literal([][][]factor.Value{{[]factor.Value{factor.S("x")}, one}, {nil, []factor.Value{factor.S("x")}}})
+	vs := []struct {
+		n    int
+		want string
+	}{
+		{0, "[[1, 0], [0, 1]]"},
+		{1, "[[x, 1], [0, x]]"},
+		{2, "[[x^2, 2*x], [0, x^2]]"},
+		{5, "[[x^5, 5*x^4], [0, x^5]]"},
+	}
+	for i, v := range vs {
+		got, err := j.Pow(v.n)
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+			continue
+		}
+		if got.String() != v.want {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.want)
+		}
+	}
+	m := symbols(3, 3)
+	p, _ := m.Pow(6)
+	if want := m.Mx(m).Mx(m).Mx(m).Mx(m).Mx(m); p.String() != want.String() {
+		t.Errorf("m^6 differs from repeated multiplication")
+	}
+	if _, err := m.Pow(-1); err == nil {
+		t.Error("negative power accepted")
+	}
+	if _, err := symbols(2, 3).Pow(2); err == nil {
+		t.Error("non-square matrix accepted")
+	}
+}
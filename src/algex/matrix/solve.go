@@ -0,0 +1,239 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// ErrInconsistent indicates that a linear system has no solution.
+var ErrInconsistent = errors.New("inconsistent linear system")
+
+// echelon is the result of fraction-free Gauss-Jordan elimination.
+type echelon struct {
+	// a holds the reduced rows. Every pivot element equals d, and
+	// the other elements of pivot columns are zero.
+	a [][]*terms.Exp
+	// pivots lists the pivot column of each of the leading rows.
+	pivots []int
+	// d is the common value of the pivots.
+	d *terms.Exp
+	// conds lists the pivots that were assumed to be non-zero.
+	conds []*terms.Exp
+}
+
+// nonZero indicates that e cannot vanish: it is a single term whose
+// symbols are all generic.
+func nonZero(e *terms.Exp, generic map[string]bool) bool {
+	ts := e.Terms()
+	if len(ts) != 1 {
+		return false
+	}
+	for _, f := range ts[0][1:] {
+		if !generic[f.Sym()] {
+			return false
+		}
+	}
+	return true
+}
+
+// condition returns the factor of a pivot that may vanish, by removing
+// the largest monomial in generic symbols that divides it.
+func condition(e *terms.Exp, generic map[string]bool) *terms.Exp {
+	low := make(map[string]int)
+	ts := e.Terms()
+	for i, t := range ts {
+		ps := make(map[string]int)
+		for _, f := range t[1:] {
+			ps[f.Sym()] = f.Pow()
+		}
+		for s := range generic {
+			if i == 0 || ps[s] < low[s] {
+				low[s] = ps[s]
+			}
+		}
+	}
+	m := []factor.Value{factor.D(1, 1)}
+	for s, p := range low {
+		if p != 0 {
+			m = append(m, factor.Sp(s, -p))
+		}
+	}
+	return terms.Mul(e, terms.NewExp(m))
+}
+
+// eliminate performs fraction-free Gauss-Jordan elimination on the rows
+// of a, considering only the first n columns as pivot candidates. The
+// rows of a are overwritten. Elements are treated as non-zero unless
+// they are identically zero, but pivots known to be non-zero, because
+// they are monomials in the generic symbols, are preferred. The other
+// pivots are recorded as conditions.
+func eliminate(a [][]*terms.Exp, n int, generic map[string]bool) (*echelon, error) {
+	e := &echelon{
+		a: a,
+		d: terms.NewExp([]factor.Value{factor.D(1, 1)}),
+	}
+	seen := make(map[string]bool)
+	r := 0
+	for c := 0; c < n && r < len(a); c++ {
+		p := -1
+		for i := r; i < len(a); i++ {
+			if a[i][c].IsZero() {
+				continue
+			}
+			if nonZero(a[i][c], generic) {
+				p = i
+				break
+			}
+			if p < 0 {
+				p = i
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		a[r], a[p] = a[p], a[r]
+		piv := a[r][c]
+		if !nonZero(piv, generic) {
+			x := condition(piv, generic)
+			if k := x.String(); !seen[k] {
+				seen[k] = true
+				e.conds = append(e.conds, x)
+			}
+		}
+		for i := range a {
+			if i == r {
+				continue
+			}
+			for j := range a[i] {
+				if j == c {
+					continue
+				}
+				x := terms.Sub(terms.Mul(piv, a[i][j]), terms.Mul(a[i][c], a[r][j]))
+				q, err := terms.Quo(x, e.d)
+				if err != nil {
+					return nil, fmt.Errorf("elimination failed: %v", err)
+				}
+				a[i][j] = q
+			}
+			a[i][c] = terms.NewExp()
+		}
+		e.d = piv
+		e.pivots = append(e.pivots, c)
+		r++
+	}
+	return e, nil
+}
+
+// null returns a basis for the nullspace of the first n columns of the
+// eliminated rows, and the free column of each basis vector.
+func (e *echelon) null(n int) ([]*Matrix, []int) {
+	pivot := make(map[int]bool)
+	for _, c := range e.pivots {
+		pivot[c] = true
+	}
+	var basis []*Matrix
+	var free []int
+	for f := 0; f < n; f++ {
+		if pivot[f] {
+			continue
+		}
+		v, _ := NewMatrix(n, 1)
+		v.Set(f, 0, e.d)
+		for i, c := range e.pivots {
+			v.Set(c, 0, terms.Sub(terms.NewExp(), e.a[i][f]))
+		}
+		basis = append(basis, v)
+		free = append(free, f)
+	}
+	return basis, free
+}
+
+// rowsOf returns the elements of a matrix as rows of expressions, with
+// nil elements replaced by zero.
+func (m *Matrix) rowsOf() [][]*terms.Exp {
+	a := make([][]*terms.Exp, m.rows)
+	for r := range a {
+		a[r] = make([]*terms.Exp, m.cols)
+		for c := range a[r] {
+			if e := m.el(r, c); e != nil {
+				a[r][c] = e
+			} else {
+				a[r][c] = terms.NewExp()
+			}
+		}
+	}
+	return a
+}
+
+// Solution describes the solutions of a linear system A x = b. They are
+// the family x = X/Den + t_0*Null[0] + t_1*Null[1] + ... for arbitrary
+// values of the parameters t_i.
+type Solution struct {
+	// X and Den give a particular solution, X/Den. Den is 1 when X
+	// is itself a solution.
+	X   *Matrix
+	Den *terms.Exp
+	// Null is a basis of column vectors for the nullspace of A. It
+	// is empty when the solution is unique.
+	Null []*Matrix
+	// Free lists the unknowns used as free parameters. Null[i] has
+	// a non-zero Free[i] element, and zero for every other free
+	// unknown.
+	Free []int
+}
+
+// Solve solves the linear system A x = b, where b may have several
+// columns. Fraction-free elimination keeps intermediate expressions
+// small. Symbolic coefficients are treated as non-zero unless they are
+// identically zero. An error wrapping ErrInconsistent is returned when
+// there is no solution.
+func Solve(a, b *Matrix) (*Solution, error) {
+	if a.rows != b.rows {
+		return nil, fmt.Errorf("%w: a rows(%d) != b rows(%d)", ErrDimension, a.rows, b.rows)
+	}
+	aug, _ := HStack(a, b)
+	e, err := eliminate(aug.rowsOf(), a.cols, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(e.pivots); i < a.rows; i++ {
+		for j := a.cols; j < aug.cols; j++ {
+			if !e.a[i][j].IsZero() {
+				return nil, fmt.Errorf("%w: row %d reduces to 0 = %v", ErrInconsistent, i, e.a[i][j])
+			}
+		}
+	}
+
+	s := &Solution{Den: e.d}
+	s.X, _ = NewMatrix(a.cols, b.cols)
+	for i, c := range e.pivots {
+		for j := 0; j < b.cols; j++ {
+			s.X.Set(c, j, e.a[i][a.cols+j])
+		}
+	}
+	if x, ok := divide(s.X, s.Den); ok {
+		s.X, s.Den = x, terms.NewExp([]factor.Value{factor.D(1, 1)})
+	}
+
+	s.Null, s.Free = e.null(a.cols)
+	return s, nil
+}
+
+// divide divides every element of m exactly by d, if possible.
+func divide(m *Matrix, d *terms.Exp) (*Matrix, bool) {
+	n, _ := NewMatrix(m.rows, m.cols)
+	for i, e := range m.data {
+		if e.IsZero() {
+			continue
+		}
+		q, err := terms.Quo(e, d)
+		if err != nil {
+			return nil, false
+		}
+		n.data[i] = q
+	}
+	return n, true
+}
@@ -0,0 +1,135 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestSolve(t *testing.T) {
+	n := func(x int64) []factor.Value { return []factor.Value{factor.D(x, 1)} }
+	s := func(x string) []factor.Value { return []factor.Value{factor.S(x)} }
+	vs := []struct {
+		a, b       *Matrix
+		x, den     string
+		null, free string
+	}{
+		{
+			a:   literal([][][]factor.Value{{n(2), n(1)}, {n(1), n(3)}}),
+			b:   literal([][][]factor.Value{{n(3)}, {n(5)}}),
+			x:   "[[4/5], [7/5]]",
+			den: "1",
+		},
+		{
+			a:   literal([][][]factor.Value{{s("a"), s("b")}, {s("c"), s("d")}}),
+			b:   literal([][][]factor.Value{{s("e")}, {s("f")}}),
+			x:   "[[-b*f+d*e], [a*f-c*e]]",
+			den: "a*d-b*c",
+		},
+		{
+			a:    literal([][][]factor.Value{{n(1), n(1), n(1)}}),
+			b:    literal([][][]factor.Value{{s("s")}}),
+			x:    "[[s], [0], [0]]",
+			den:  "1",
+			null: "[[[-1], [1], [0]] [[-1], [0], [1]]]",
+			free: "[1 2]",
+		},
+		{
+			a:   literal([][][]factor.Value{{n(1)}, {n(2)}}),
+			b:   literal([][][]factor.Value{{s("a")}, {[]factor.Value{factor.D(2, 1), factor.S("a")}}}),
+			x:   "[[a]]",
+			den: "1",
+		},
+		{
+			a:    literal([][][]factor.Value{{s("a"), s("b")}, {s("a"), s("b")}}),
+			b:    literal([][][]factor.Value{{s("c")}, {s("c")}}),
+			x:    "[[a^-1*c], [0]]",
+			den:  "1",
+			null: "[[[-b], [a]]]",
+			free: "[1]",
+		},
+	}
+	for i, v := range vs {
+		sol, err := Solve(v.a, v.b)
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+			continue
+		}
+		if got := sol.X.String(); got != v.x {
+			t.Errorf("[%d] got x=%q want=%q", i, got, v.x)
+		}
+		if got := sol.Den.String(); got != v.den {
+			t.Errorf("[%d] got den=%q want=%q", i, got, v.den)
+		}
+		null, free := "", ""
+		if len(sol.Null) != 0 {
+			null, free = fmt.Sprint(sol.Null), fmt.Sprint(sol.Free)
+		}
+		if null != v.null || free != v.free {
+			t.Errorf("[%d] got null=%s free=%s want=%s, %s", i, null, free, v.null, v.free)
+		}
+		ax := v.a.Mx(sol.X)
+		rows, _ := v.b.Dims()
+		for r := 0; r < rows; r++ {
+			if got, want := ax.el(r, 0).String(), terms.Mul(v.b.el(r, 0), sol.Den).String(); got != want {
+				t.Errorf("[%d] (a*x)[%d]=%q want=%q", i, r, got, want)
+			}
+		}
+		for j, x := range sol.Null {
+			z := v.a.Mx(x)
+			for r := 0; r < rows; r++ {
+				if !z.el(r, 0).IsZero() {
+					t.Errorf("[%d] (a*null[%d])[%d]=%v, want 0", i, j, r, z.el(r, 0))
+				}
+			}
+		}
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	one := []factor.Value{factor.D(1, 1)}
+	a := literal([][][]factor.Value{{one}, {one}})
+	b := literal([][][]factor.Value{{[]factor.Value{factor.S("a")}}, {[]factor.Value{factor.S("b")}}})
+	if _, err := Solve(a, b); !errors.Is(err, ErrInconsistent) {
+		t.Errorf("got %v, want ErrInconsistent", err)
+	}
+	if _, err := Solve(a, identity(3)); err == nil {
+		t.Error("mismatched rows were accepted")
+	}
+}
+
+func TestLaurentSolve(t *testing.T) {
+	a := laurent(5)
+	b, _ := NewMatrix(5, 1)
+	for r := 0; r < 5; r++ {
+		b.Set(r, 0, exp(factor.Sp("x", -r)))
+	}
+	sol, err := Solve(a, b)
+	if err != nil {
+		t.Fatalf("solve failed: %v", err)
+	}
+	if got, want := a.Mx(sol.X), b.Scale(sol.Den); !Equal(got, want) {
+		t.Errorf("a*x got=%v want=%v", got, want)
+	}
+
+	// The last row of s is x^-1 times its first, so s*x = b only has a
+	// solution when b is too.
+	s := singular(5)
+	if _, err := Solve(s, b); !errors.Is(err, ErrInconsistent) {
+		t.Errorf("inconsistent solve got %v, want ErrInconsistent", err)
+	}
+	b.Set(4, 0, terms.Mul(b.el(0, 0), exp(factor.Sp("x", -1))))
+	sol, err = Solve(s, b)
+	if err != nil {
+		t.Fatalf("consistent solve failed: %v", err)
+	}
+	if got, want := s.Mx(sol.X), b.Scale(sol.Den); !Equal(got, want) {
+		t.Errorf("s*x got=%v want=%v", got, want)
+	}
+	if len(sol.Null) != 1 {
+		t.Errorf("got null space %v, want one vector", sol.Null)
+	}
+}
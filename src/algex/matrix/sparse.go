@@ -0,0 +1,208 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// Sparse is a matrix that only stores its non-zero elements. It offers
+// the same operations as Matrix, but their cost is proportional to the
+// number of non-zero elements rather than the dimensions.
+type Sparse struct {
+	// row count and col count
+	rows, cols int
+	// The non-zero elements of each row indexed by column.
+	data []map[int]*terms.Exp
+}
+
+// NewSparse creates a rows x cols sparse matrix of zeros.
+func NewSparse(rows, cols int) (*Sparse, error) {
+	if rows <= 0 || cols <= 0 {
+		return nil, fmt.Errorf("%w: need positive dimensions, not %dx%d", ErrDimension, rows, cols)
+	}
+	m := &Sparse{
+		rows: rows,
+		cols: cols,
+		data: make([]map[int]*terms.Exp, rows),
+	}
+	for r := range m.data {
+		m.data[r] = make(map[int]*terms.Exp)
+	}
+	return m, nil
+}
+
+// Sparse converts a matrix to a sparse matrix.
+func (m *Matrix) Sparse() *Sparse {
+	s, _ := NewSparse(m.rows, m.cols)
+	for r := 0; r < m.rows; r++ {
+		for c := 0; c < m.cols; c++ {
+			s.Set(r, c, m.el(r, c))
+		}
+	}
+	return s
+}
+
+// Dense converts a sparse matrix to a matrix.
+func (m *Sparse) Dense() *Matrix {
+	d, _ := NewMatrix(m.rows, m.cols)
+	for r, row := range m.data {
+		for c, e := range row {
+			d.Set(r, c, e)
+		}
+	}
+	return d
+}
+
+// Dims returns the number of rows and columns of a matrix.
+func (m *Sparse) Dims() (rows, cols int) {
+	return m.rows, m.cols
+}
+
+// NonZero returns the number of non-zero elements of a matrix.
+func (m *Sparse) NonZero() int {
+	n := 0
+	for _, row := range m.data {
+		n += len(row)
+	}
+	return n
+}
+
+// String serializes a matrix for displaying, in the same form as
+// Matrix.
+func (m *Sparse) String() string {
+	return format(m)
+}
+
+// Set sets the value of a matrix element. Setting an element to nil or
+// zero removes it.
+func (m *Sparse) Set(row, col int, e *terms.Exp) error {
+	if row < 0 || col < 0 || row >= m.rows || col >= m.cols {
+		return fmt.Errorf("%w: [%d,%d] in %dx%d matrix", ErrBounds, row, col, m.rows, m.cols)
+	}
+	if e.IsZero() {
+		delete(m.data[row], col)
+		return nil
+	}
+	m.data[row][col] = e
+	return nil
+}
+
+// El returns the row,col element of the matrix, which is nil if it is
+// zero.
+func (m *Sparse) El(row, col int) (*terms.Exp, error) {
+	if row < 0 || col < 0 || row >= m.rows || col >= m.cols {
+		return nil, fmt.Errorf("%w: [%d,%d] in %dx%d matrix", ErrBounds, row, col, m.rows, m.cols)
+	}
+	return m.el(row, col), nil
+}
+
+// el returns the row,col element of the matrix without checking the
+// indices.
+func (m *Sparse) el(row, col int) *terms.Exp {
+	return m.data[row][col]
+}
+
+// Mul multiplies m x n with conventional matrix multiplication. Only
+// products of non-zero elements are formed.
+func (m *Sparse) Mul(n *Sparse) (*Sparse, error) {
+	if m.cols != n.rows {
+		return nil, fmt.Errorf("%w: a cols(%d) != b rows(%d)", ErrDimension, m.cols, n.rows)
+	}
+	a, _ := NewSparse(m.rows, n.cols)
+	for r, row := range m.data {
+		es := make(map[int][]*terms.Exp)
+		for i, x := range row {
+			for c, y := range n.data[i] {
+				es[c] = append(es[c], terms.Mul(x, y))
+			}
+		}
+		for c, e := range es {
+			a.Set(r, c, terms.Add(e...))
+		}
+	}
+	return a, nil
+}
+
+// Mx multiplies two matrices and panics on error.
+func (m *Sparse) Mx(n *Sparse) *Sparse {
+	a, err := m.Mul(n)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Sum adds two matrices, with the elements of n multiplied by scale.
+func (m *Sparse) Sum(n *Sparse, scale *terms.Exp) (*Sparse, error) {
+	if m.rows != n.rows || m.cols != n.cols {
+		return nil, fmt.Errorf("%w: inequivalent dimensions %dx%d != %dx%d", ErrDimension, m.rows, m.cols, n.rows, n.cols)
+	}
+	a, _ := NewSparse(m.rows, m.cols)
+	for r := range m.data {
+		for c, p := range m.data[r] {
+			a.data[r][c] = p
+		}
+		for c, q := range n.data[r] {
+			if p, ok := a.data[r][c]; ok {
+				a.Set(r, c, terms.Add(p, terms.Mul(q, scale)))
+			} else {
+				a.Set(r, c, terms.Mul(q, scale))
+			}
+		}
+	}
+	return a, nil
+}
+
+// Add adds two matrices, and panics on error.
+func (m *Sparse) Add(n *Sparse, scale *terms.Exp) *Sparse {
+	a, err := m.Sum(n, scale)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Substitute performs a substitution on all non-zero elements of a
+// matrix. It fails as terms.Substitute does for any element.
+func (m *Sparse) Substitute(b []factor.Value, s *terms.Exp) (*Sparse, error) {
+	n, _ := NewSparse(m.rows, m.cols)
+	for r, row := range m.data {
+		for c, e := range row {
+			x, err := terms.Substitute(e, b, s)
+			if err != nil {
+				return nil, fmt.Errorf("element (%d,%d): %w", r, c, err)
+			}
+			n.Set(r, c, x)
+		}
+	}
+	return n, nil
+}
+
+// Transpose returns the transpose of a matrix.
+func (m *Sparse) Transpose() *Sparse {
+	t, _ := NewSparse(m.cols, m.rows)
+	for r, row := range m.data {
+		for c, e := range row {
+			t.data[c][r] = e
+		}
+	}
+	return t
+}
+
+// Entries calls fn for each non-zero element of a matrix, in row-major
+// order.
+func (m *Sparse) Entries(fn func(row, col int, e *terms.Exp)) {
+	for r, row := range m.data {
+		var cs []int
+		for c := range row {
+			cs = append(cs, c)
+		}
+		sort.Ints(cs)
+		for _, c := range cs {
+			fn(r, c, row[c])
+		}
+	}
+}
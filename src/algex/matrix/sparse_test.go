@@ -0,0 +1,95 @@
+package matrix
+
+import (
+	"fmt"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// band returns an n x n sparse tridiagonal matrix with symbolic
+// elements.
+func band(n int) *Sparse {
+	m, _ := NewSparse(n, n)
+	for i := 0; i < n; i++ {
+		for j := i - 1; j <= i+1; j++ {
+			if j >= 0 && j < n {
+				m.Set(i, j, terms.NewExp([]factor.Value{factor.S(fmt.Sprintf("m%d_%d", i, j))}))
+			}
+		}
+	}
+	return m
+}
+
+func TestSparse(t *testing.T) {
+	d := symbols(2, 3)
+	d.Set(0, 1, nil)
+	s := d.Sparse()
+	if got, want := s.String(), d.String(); got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	if got := s.NonZero(); got != 5 {
+		t.Errorf("got %d non-zero elements, want 5", got)
+	}
+	if got, want := s.Dense().String(), d.String(); got != want {
+		t.Errorf("dense got=%q want=%q", got, want)
+	}
+	if got, want := s.Transpose().String(), d.Transpose().String(); got != want {
+		t.Errorf("transpose got=%q want=%q", got, want)
+	}
+	if err := s.Set(2, 0, nil); err == nil {
+		t.Error("out of range Set accepted")
+	}
+	s.Set(0, 0, terms.NewExp())
+	if s.el(0, 0) != nil || s.NonZero() != 4 {
+		t.Errorf("setting zero did not remove the element: %v", s)
+	}
+	var got []string
+	s.Entries(func(r, c int, e *terms.Exp) {
+		got = append(got, fmt.Sprintf("%d,%d=%v", r, c, e))
+	})
+	if want := "[0,2=c 1,0=d 1,1=e 1,2=f]"; fmt.Sprint(got) != want {
+		t.Errorf("entries got=%v want=%s", got, want)
+	}
+}
+
+func TestSparseArithmetic(t *testing.T) {
+	a, b := band(5), band(5).Transpose()
+	p, err := a.Mul(b)
+	if err != nil {
+		t.Fatalf("mul failed: %v", err)
+	}
+	if got, want := p.String(), a.Dense().Mx(b.Dense()).String(); got != want {
+		t.Errorf("mul got=%q want=%q", got, want)
+	}
+	if got := p.NonZero(); got != 19 {
+		t.Errorf("product has %d non-zero elements, want 19", got)
+	}
+	minus := terms.NewExp([]factor.Value{factor.D(-1, 1)})
+	if got := a.Add(a, minus).NonZero(); got != 0 {
+		t.Errorf("a-a has %d non-zero elements", got)
+	}
+	sum, err := a.Sum(b, minus)
+	if err != nil {
+		t.Fatalf("sum failed: %v", err)
+	}
+	if got, want := sum.String(), a.Dense().Add(b.Dense(), minus).String(); got != want {
+		t.Errorf("sum got=%q want=%q", got, want)
+	}
+	x := []factor.Value{factor.S("m0_0")}
+	r := terms.NewExp([]factor.Value{factor.S("z")})
+	got, err := a.Substitute(x, r)
+	if err != nil {
+		t.Fatalf("substitute failed: %v", err)
+	}
+	if want, _ := a.Dense().Substitute(x, r); got.String() != want.String() {
+		t.Errorf("substitute got=%q want=%q", got, want)
+	}
+	if _, err := a.Mul(band(4)); err == nil {
+		t.Error("mismatched dimensions multiplied")
+	}
+	if _, err := a.Sum(band(4), minus); err == nil {
+		t.Error("mismatched dimensions summed")
+	}
+}
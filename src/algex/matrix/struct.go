@@ -0,0 +1,146 @@
+package matrix
+
+import (
+	"fmt"
+
+	"algex/terms"
+)
+
+// Range is a half open range of row or column indices, [From, To).
+type Range struct {
+	From, To int
+}
+
+// Transpose returns the transpose of a matrix.
+func (m *Matrix) Transpose() *Matrix {
+	t, _ := NewMatrix(m.cols, m.rows)
+	for r := 0; r < m.rows; r++ {
+		for c := 0; c < m.cols; c++ {
+			t.Set(c, r, m.el(r, c))
+		}
+	}
+	return t
+}
+
+// Trace returns the sum of the diagonal elements of a square matrix.
+func (m *Matrix) Trace() (*terms.Exp, error) {
+	if m.rows != m.cols {
+		return nil, fmt.Errorf("%w: need a square matrix, not %dx%d", ErrDimension, m.rows, m.cols)
+	}
+	var es []*terms.Exp
+	for i := 0; i < m.rows; i++ {
+		if e := m.el(i, i); e != nil {
+			es = append(es, e)
+		}
+	}
+	return terms.Add(es...), nil
+}
+
+// Submatrix returns the elements of a matrix in the given ranges of
+// rows and columns.
+func (m *Matrix) Submatrix(rows, cols Range) (*Matrix, error) {
+	if rows.From < 0 || rows.To > m.rows || cols.From < 0 || cols.To > m.cols {
+		return nil, fmt.Errorf("%w: range [%d:%d,%d:%d] in %dx%d matrix", ErrBounds, rows.From, rows.To, cols.From, cols.To, m.rows, m.cols)
+	}
+	s, err := NewMatrix(rows.To-rows.From, cols.To-cols.From)
+	if err != nil {
+		return nil, err
+	}
+	for r := 0; r < s.rows; r++ {
+		for c := 0; c < s.cols; c++ {
+			s.Set(r, c, m.el(rows.From+r, cols.From+c))
+		}
+	}
+	return s, nil
+}
+
+// Row returns row i of a matrix as a 1xN matrix.
+func (m *Matrix) Row(i int) (*Matrix, error) {
+	return m.Submatrix(Range{i, i + 1}, Range{0, m.cols})
+}
+
+// Col returns column j of a matrix as an Nx1 matrix.
+func (m *Matrix) Col(j int) (*Matrix, error) {
+	return m.Submatrix(Range{0, m.rows}, Range{j, j + 1})
+}
+
+// Minor returns the matrix formed by deleting one row and one column
+// of a matrix. The determinant of this matrix is the (row, col) minor.
+func (m *Matrix) Minor(row, col int) (*Matrix, error) {
+	if row < 0 || col < 0 || row >= m.rows || col >= m.cols {
+		return nil, fmt.Errorf("%w: [%d,%d] in %dx%d matrix", ErrBounds, row, col, m.rows, m.cols)
+	}
+	n, err := NewMatrix(m.rows-1, m.cols-1)
+	if err != nil {
+		return nil, err
+	}
+	for r := 0; r < n.rows; r++ {
+		for c := 0; c < n.cols; c++ {
+			i, j := r, c
+			if i >= row {
+				i++
+			}
+			if j >= col {
+				j++
+			}
+			n.Set(r, c, m.el(i, j))
+		}
+	}
+	return n, nil
+}
+
+// HStack joins matrices with the same number of rows side by side.
+func HStack(ms ...*Matrix) (*Matrix, error) {
+	return Block([][]*Matrix{ms})
+}
+
+// VStack joins matrices with the same number of columns one above the
+// other.
+func VStack(ms ...*Matrix) (*Matrix, error) {
+	var bs [][]*Matrix
+	for _, m := range ms {
+		bs = append(bs, []*Matrix{m})
+	}
+	return Block(bs)
+}
+
+// Block assembles a matrix from rows of sub-matrices. The blocks in
+// each row must have the same number of rows, and each row of blocks
+// must have the same total number of columns.
+func Block(bs [][]*Matrix) (*Matrix, error) {
+	rows, cols := 0, 0
+	for i, br := range bs {
+		if len(br) == 0 {
+			return nil, fmt.Errorf("%w: block row %d is empty", ErrDimension, i)
+		}
+		n := 0
+		for j, b := range br {
+			if b.rows != br[0].rows {
+				return nil, fmt.Errorf("%w: block [%d,%d] has %d rows, not %d", ErrDimension, i, j, b.rows, br[0].rows)
+			}
+			n += b.cols
+		}
+		if i != 0 && n != cols {
+			return nil, fmt.Errorf("%w: block row %d has %d columns, not %d", ErrDimension, i, n, cols)
+		}
+		rows, cols = rows+br[0].rows, n
+	}
+	a, err := NewMatrix(rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	r0 := 0
+	for _, br := range bs {
+		c0 := 0
+		for _, b := range br {
+			for r := 0; r < b.rows; r++ {
+				for c := 0; c < b.cols; c++ {
+					a.Set(r0+r, c0+c, b.el(r, c))
+				}
+			}
+			c0 += b.cols
+		}
+		r0 += br[0].rows
+	}
+	return a, nil
+}
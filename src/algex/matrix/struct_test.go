@@ -0,0 +1,52 @@
+package matrix
+
+import "testing"
+
+func TestStructure(t *testing.T) {
+	m := symbols(2, 3)
+	result := func(x *Matrix, err error) func() (*Matrix, error) {
+		return func() (*Matrix, error) { return x, err }
+	}
+	vs := []struct {
+		name string
+		f    func() (*Matrix, error)
+		want string
+	}{
+		{name: "transpose", f: result(m.Transpose(), nil), want: "[[a, d], [b, e], [c, f]]"},
+		{name: "submatrix", f: result(m.Submatrix(Range{0, 2}, Range{1, 3})), want: "[[b, c], [e, f]]"},
+		{name: "row", f: result(m.Row(1)), want: "[[d, e, f]]"},
+		{name: "col", f: result(m.Col(2)), want: "[[c], [f]]"},
+		{name: "minor", f: result(m.Minor(1, 1)), want: "[[a, c]]"},
+		{name: "hstack", f: result(HStack(m, symbols(2, 1))), want: "[[a, b, c, a], [d, e, f, b]]"},
+		{name: "vstack", f: result(VStack(m, symbols(1, 3))), want: "[[a, b, c], [d, e, f], [a, b, c]]"},
+		{name: "block", f: result(Block([][]*Matrix{{m, symbols(2, 1)}, {symbols(1, 4)}})), want: "[[a, b, c, a], [d, e, f, b], [a, b, c, d]]"},
+	}
+	for _, v := range vs {
+		x, err := v.f()
+		if err != nil {
+			t.Errorf("%s failed: %v", v.name, err)
+		} else if got := x.String(); got != v.want {
+			t.Errorf("%s got=%q want=%q", v.name, got, v.want)
+		}
+	}
+
+	if tr, err := symbols(2, 2).Trace(); err != nil || tr.String() != "a+d" {
+		t.Errorf("trace got=%v, %v", tr, err)
+	}
+
+	for i, err := range []error{
+		func() error { _, err := m.Trace(); return err }(),
+		func() error { _, err := m.Row(2); return err }(),
+		func() error { _, err := m.Col(-1); return err }(),
+		func() error { _, err := m.Submatrix(Range{1, 1}, Range{0, 3}); return err }(),
+		func() error { _, err := m.Minor(0, 3); return err }(),
+		func() error { _, err := symbols(1, 1).Minor(0, 0); return err }(),
+		func() error { _, err := HStack(m, symbols(3, 1)); return err }(),
+		func() error { _, err := VStack(m, symbols(1, 2)); return err }(),
+		func() error { _, err := Block([][]*Matrix{{m}, {}}); return err }(),
+	} {
+		if err == nil {
+			t.Errorf("[%d] expected an error", i)
+		}
+	}
+}
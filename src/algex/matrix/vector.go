@@ -0,0 +1,98 @@
+package matrix
+
+import (
+	"fmt"
+
+	"algex/terms"
+)
+
+// Vector returns a column vector holding the expressions es. A vector
+// needs at least one element.
+func Vector(es ...*terms.Exp) (*Matrix, error) {
+	m, err := NewMatrix(len(es), 1)
+	if err != nil {
+		return nil, err
+	}
+	copy(m.data, es)
+	return m, nil
+}
+
+// vector confirms that m is a column vector of dimension n, or of any
+// dimension if n is zero.
+func (m *Matrix) vector(n int) error {
+	if m.cols != 1 || (n != 0 && m.rows != n) {
+		if n == 0 {
+			return fmt.Errorf("%w: need a column vector, not %dx%d", ErrDimension, m.rows, m.cols)
+		}
+		return fmt.Errorf("%w: need a %dx1 column vector, not %dx%d", ErrDimension, n, m.rows, m.cols)
+	}
+	return nil
+}
+
+// at returns the element of a vector, with nil replaced by zero.
+func (m *Matrix) at(i int) *terms.Exp {
+	if e := m.data[i]; e != nil {
+		return e
+	}
+	return terms.NewExp()
+}
+
+// Dot returns the dot product of two column vectors.
+func (m *Matrix) Dot(n *Matrix) (*terms.Exp, error) {
+	if err := m.vector(0); err != nil {
+		return nil, err
+	}
+	if err := n.vector(m.rows); err != nil {
+		return nil, err
+	}
+	var xs []*terms.Exp
+	for i := 0; i < m.rows; i++ {
+		xs = append(xs, terms.Mul(m.at(i), n.at(i)))
+	}
+	return terms.Add(xs...), nil
+}
+
+// NormSquared returns the dot product of a column vector with itself.
+func (m *Matrix) NormSquared() (*terms.Exp, error) {
+	return m.Dot(m)
+}
+
+// Cross returns the cross product of two 3x1 column vectors.
+func (m *Matrix) Cross(n *Matrix) (*Matrix, error) {
+	s, err := m.Skew()
+	if err != nil {
+		return nil, err
+	}
+	if err := n.vector(3); err != nil {
+		return nil, err
+	}
+	return s.Mx(n), nil
+}
+
+// Outer returns the outer product of two column vectors, m*n^T.
+func (m *Matrix) Outer(n *Matrix) (*Matrix, error) {
+	if err := m.vector(0); err != nil {
+		return nil, err
+	}
+	if err := n.vector(0); err != nil {
+		return nil, err
+	}
+	return m.Mx(n.Transpose()), nil
+}
+
+// Skew returns the skew-symmetric matrix of a 3x1 column vector, v,
+// such that Skew(v)*u is the cross product of v and u.
+func (m *Matrix) Skew() (*Matrix, error) {
+	if err := m.vector(3); err != nil {
+		return nil, err
+	}
+	neg := func(i int) *terms.Exp { return terms.Sub(terms.NewExp(), m.at(i)) }
+	s, _ := NewMatrix(3, 3)
+	s.Set(0, 1, neg(2))
+	s.Set(0, 2, m.at(1))
+	s.Set(1, 0, m.at(2))
+	s.Set(1, 2, neg(0))
+	s.Set(2, 0, neg(1))
+	s.Set(2, 1, m.at(0))
+	return s, nil
+}
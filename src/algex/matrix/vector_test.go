@@ -0,0 +1,76 @@
+package matrix
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+// vec returns a column vector of single symbol expressions.
+func vec(syms ...string) *Matrix {
+	var es []*terms.Exp
+	for _, s := range syms {
+		es = append(es, terms.NewExp([]factor.Value{factor.S(s)}))
+	}
+	m, _ := Vector(es...)
+	return m
+}
+
+func TestVector(t *testing.T) {
+	if v, err := Vector(); err == nil {
+		t.Errorf("empty vector got=%v", v)
+	}
+	a, b := vec("a", "b", "c"), vec("x", "y", "z")
+	if got, err := a.Dot(b); err != nil || got.String() != "a*x+b*y+c*z" {
+		t.Errorf("dot got=%v (%v)", got, err)
+	}
+	if got, err := a.NormSquared(); err != nil || got.String() != "a^2+b^2+c^2" {
+		t.Errorf("norm squared got=%v (%v)", got, err)
+	}
+	c, err := a.Cross(b)
+	if err != nil {
+		t.Fatalf("cross failed: %v", err)
+	}
+	if got, want := c.String(), "[[b*z-c*y], [-a*z+c*x], [a*y-b*x]]"; got != want {
+		t.Errorf("cross got=%q want=%q", got, want)
+	}
+	for i, v := range []*Matrix{a, b} {
+		if d, _ := c.Dot(v); !d.IsZero() {
+			t.Errorf("[%d] cross product not orthogonal: %v", i, d)
+		}
+	}
+	s, err := a.Skew()
+	if err != nil {
+		t.Fatalf("skew failed: %v", err)
+	}
+	if got, want := s.String(), "[[0, -c, b], [c, 0, -a], [-b, a, 0]]"; got != want {
+		t.Errorf("skew got=%q want=%q", got, want)
+	}
+	if got, want := s.Transpose().Scale(terms.NewExp([]factor.Value{factor.D(-1, 1)})).String(), s.String(); got != want {
+		t.Errorf("skew not skew-symmetric: %q", got)
+	}
+	o, err := vec("a", "b").Outer(vec("x", "y", "z"))
+	if err != nil {
+		t.Fatalf("outer failed: %v", err)
+	}
+	if got, want := o.String(), "[[a*x, a*y, a*z], [b*x, b*y, b*z]]"; got != want {
+		t.Errorf("outer got=%q want=%q", got, want)
+	}
+}
+
+func TestVectorErrors(t *testing.T) {
+	a, b := vec("a", "b"), vec("x", "y", "z")
+	if _, err := a.Dot(b); err == nil {
+		t.Error("dot of mismatched vectors accepted")
+	}
+	if _, err := a.Cross(a); err == nil {
+		t.Error("cross of 2-vectors accepted")
+	}
+	if _, err := symbols(3, 3).Skew(); err == nil {
+		t.Error("skew of a matrix accepted")
+	}
+	if _, err := symbols(1, 3).Outer(b); err == nil {
+		t.Error("outer of a row vector accepted")
+	}
+}
@@ -0,0 +1,322 @@
+// Package openmath imports and exports expressions and matrices as
+// OpenMath XML.
+//
+// Only the subset of OpenMath that algex can represent is supported:
+// integers, rationals, variables, integer powers, sums, differences,
+// products, division by single terms and matrices.
+package openmath
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"algex/factor"
+	"algex/matrix"
+	"algex/terms"
+)
+
+// Namespace is the OpenMath XML namespace.
+const Namespace = "http://www.openmath.org/OpenMath"
+
+// object wraps body in a top level OpenMath object.
+func object(body string) []byte {
+	return []byte(`<OMOBJ xmlns="` + Namespace + `">` + body + `</OMOBJ>`)
+}
+
+// oms returns an OpenMath symbol from a content dictionary.
+func oms(cd, name string) string {
+	return `<OMS cd="` + cd + `" name="` + name + `"/>`
+}
+
+// Marshal returns the OpenMath form of an expression.
+func Marshal(e *terms.Exp) []byte {
+	return object(exp(e))
+}
+
+// MarshalMatrix returns the OpenMath form of a matrix.
+func MarshalMatrix(m *matrix.Matrix) []byte {
+	rows, cols := m.Dims()
+	var b strings.Builder
+	b.WriteString(`<OMA>` + oms("linalg2", "matrix"))
+	for r := 0; r < rows; r++ {
+		b.WriteString(`<OMA>` + oms("linalg2", "matrixrow"))
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			b.WriteString(exp(e))
+		}
+		b.WriteString(`</OMA>`)
+	}
+	b.WriteString(`</OMA>`)
+	return object(b.String())
+}
+
+// num returns the OpenMath form of a rational number.
+func num(n *big.Rat) string {
+	if n.IsInt() {
+		return `<OMI>` + n.Num().String() + `</OMI>`
+	}
+	return `<OMA>` + oms("nums1", "rational") + `<OMI>` + n.Num().String() + `</OMI><OMI>` + n.Denom().String() + `</OMI></OMA>`
+}
+
+// exp returns the OpenMath form of an expression.
+func exp(e *terms.Exp) string {
+	ts := e.Terms()
+	if len(ts) == 0 {
+		return num(&big.Rat{})
+	}
+	var xs []string
+	for _, t := range ts {
+		var fs []string
+		if len(t) == 1 || t[0].Num().Cmp(big.NewRat(1, 1)) != 0 {
+			fs = append(fs, num(t[0].Num()))
+		}
+		for _, f := range t[1:] {
+			var b strings.Builder
+			b.WriteString(`<OMV name="`)
+			xml.EscapeText(&b, []byte(f.Sym()))
+			b.WriteString(`"/>`)
+			s := b.String()
+			if p := f.Pow(); p != 1 {
+				s = `<OMA>` + oms("arith1", "power") + s + `<OMI>` + strconv.Itoa(p) + `</OMI></OMA>`
+			}
+			fs = append(fs, s)
+		}
+		if len(fs) == 1 {
+			xs = append(xs, fs[0])
+		} else {
+			xs = append(xs, `<OMA>`+oms("arith1", "times")+strings.Join(fs, "")+`</OMA>`)
+		}
+	}
+	if len(xs) == 1 {
+		return xs[0]
+	}
+	return `<OMA>` + oms("arith1", "plus") + strings.Join(xs, "") + `</OMA>`
+}
+
+// node is a generic XML element of an OpenMath document.
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []node     `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// attr returns the value of the named attribute of n.
+func (n *node) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// value is either an expression or a matrix.
+type value struct {
+	e *terms.Exp
+	m *matrix.Matrix
+}
+
+// parse decodes an OpenMath object into a value.
+func parse(data []byte) (value, error) {
+	var n node
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&n); err != nil {
+		return value{}, err
+	}
+	if n.XMLName.Local != "OMOBJ" {
+		return value{}, fmt.Errorf("want <OMOBJ>, not <%s>", n.XMLName.Local)
+	}
+	if len(n.Nodes) != 1 {
+		return value{}, fmt.Errorf("<OMOBJ> must hold exactly one object, not %d", len(n.Nodes))
+	}
+	return eval(&n.Nodes[0])
+}
+
+// Unmarshal decodes an OpenMath object holding an expression.
+func Unmarshal(data []byte) (*terms.Exp, error) {
+	v, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if v.e == nil {
+		return nil, fmt.Errorf("object is a matrix, not an expression")
+	}
+	return v.e, nil
+}
+
+// UnmarshalMatrix decodes an OpenMath object holding a matrix.
+func UnmarshalMatrix(data []byte) (*matrix.Matrix, error) {
+	v, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if v.m == nil {
+		return nil, fmt.Errorf("object is an expression, not a matrix")
+	}
+	return v.m, nil
+}
+
+// scalar evaluates n, which must be an expression.
+func scalar(n *node) (*terms.Exp, error) {
+	v, err := eval(n)
+	if err != nil {
+		return nil, err
+	}
+	if v.e == nil {
+		return nil, fmt.Errorf("matrix not permitted as an operand")
+	}
+	return v.e, nil
+}
+
+// integer evaluates n, which must be an <OMI> that fits in an int.
+func integer(n *node) (int, error) {
+	if n.XMLName.Local != "OMI" {
+		return 0, fmt.Errorf("want <OMI>, not <%s>", n.XMLName.Local)
+	}
+	return strconv.Atoi(strings.TrimSpace(n.Text))
+}
+
+// eval converts an OpenMath element into a value.
+func eval(n *node) (value, error) {
+	switch n.XMLName.Local {
+	case "OMI":
+		i, ok := new(big.Int).SetString(strings.TrimSpace(n.Text), 10)
+		if !ok {
+			return value{}, fmt.Errorf("bad integer %q", n.Text)
+		}
+		r := &big.Rat{}
+		return value{e: terms.NewExp([]factor.Value{factor.R(r.SetInt(i))})}, nil
+	case "OMV":
+		name := n.attr("name")
+		if name == "" {
+			return value{}, fmt.Errorf("<OMV> without a name")
+		}
+		return value{e: terms.NewExp([]factor.Value{factor.S(name)})}, nil
+	case "OMA":
+	default:
+		return value{}, fmt.Errorf("unsupported element <%s>", n.XMLName.Local)
+	}
+	if len(n.Nodes) == 0 || n.Nodes[0].XMLName.Local != "OMS" {
+		return value{}, fmt.Errorf("<OMA> must start with an <OMS>")
+	}
+	head, args := n.Nodes[0], n.Nodes[1:]
+	op := head.attr("cd") + "." + head.attr("name")
+	switch op {
+	case "linalg1.matrix", "linalg2.matrix":
+		return evalMatrix(args)
+	case "nums1.rational":
+		if len(args) != 2 {
+			return value{}, fmt.Errorf("%s needs 2 arguments, not %d", op, len(args))
+		}
+		var xs [2]*big.Int
+		for i := range args {
+			if args[i].XMLName.Local != "OMI" {
+				return value{}, fmt.Errorf("%s needs <OMI> arguments", op)
+			}
+			x, ok := new(big.Int).SetString(strings.TrimSpace(args[i].Text), 10)
+			if !ok {
+				return value{}, fmt.Errorf("bad integer %q", args[i].Text)
+			}
+			xs[i] = x
+		}
+		if xs[1].Sign() == 0 {
+			return value{}, fmt.Errorf("zero denominator")
+		}
+		r := &big.Rat{}
+		return value{e: terms.NewExp([]factor.Value{factor.R(r.SetFrac(xs[0], xs[1]))})}, nil
+	case "arith1.plus", "arith1.times":
+		var es []*terms.Exp
+		for i := range args {
+			e, err := scalar(&args[i])
+			if err != nil {
+				return value{}, err
+			}
+			es = append(es, e)
+		}
+		if len(es) == 0 {
+			return value{}, fmt.Errorf("%s needs arguments", op)
+		}
+		if op == "arith1.plus" {
+			return value{e: terms.Add(es...)}, nil
+		}
+		return value{e: terms.Mul(es...)}, nil
+	case "arith1.unary_minus":
+		if len(args) != 1 {
+			return value{}, fmt.Errorf("%s needs 1 argument, not %d", op, len(args))
+		}
+		e, err := scalar(&args[0])
+		if err != nil {
+			return value{}, err
+		}
+		return value{e: terms.Sub(terms.NewExp(), e)}, nil
+	case "arith1.minus", "arith1.divide", "arith1.power":
+		if len(args) != 2 {
+			return value{}, fmt.Errorf("%s needs 2 arguments, not %d", op, len(args))
+		}
+		a, err := scalar(&args[0])
+		if err != nil {
+			return value{}, err
+		}
+		if op == "arith1.power" {
+			p, err := integer(&args[1])
+			if err != nil {
+				return value{}, fmt.Errorf("%s needs an integer exponent: %v", op, err)
+			}
+			e, err := terms.Pow(a, p)
+			return value{e: e}, err
+		}
+		b, err := scalar(&args[1])
+		if err != nil {
+			return value{}, err
+		}
+		if op == "arith1.minus" {
+			return value{e: terms.Sub(a, b)}, nil
+		}
+		d, err := terms.Pow(b, -1)
+		if err != nil {
+			return value{}, err
+		}
+		return value{e: terms.Mul(a, d)}, nil
+	}
+	return value{}, fmt.Errorf("unsupported symbol %s", op)
+}
+
+// evalMatrix converts a list of matrixrow elements into a matrix.
+func evalMatrix(rows []node) (value, error) {
+	var data [][]*terms.Exp
+	for i := range rows {
+		r := &rows[i]
+		if r.XMLName.Local != "OMA" || len(r.Nodes) == 0 || r.Nodes[0].XMLName.Local != "OMS" || r.Nodes[0].attr("name") != "matrixrow" {
+			return value{}, fmt.Errorf("matrix row %d is not a matrixrow", i)
+		}
+		var row []*terms.Exp
+		for j := range r.Nodes[1:] {
+			e, err := scalar(&r.Nodes[1+j])
+			if err != nil {
+				return value{}, err
+			}
+			row = append(row, e)
+		}
+		if len(data) != 0 && len(row) != len(data[0]) {
+			return value{}, fmt.Errorf("matrix row %d has %d columns, not %d", i, len(row), len(data[0]))
+		}
+		data = append(data, row)
+	}
+	if len(data) == 0 {
+		return value{}, fmt.Errorf("empty matrix")
+	}
+	m, err := matrix.NewMatrix(len(data), len(data[0]))
+	if err != nil {
+		return value{}, err
+	}
+	for r, row := range data {
+		for c, e := range row {
+			m.Set(r, c, e)
+		}
+	}
+	return value{m: m}, nil
+}
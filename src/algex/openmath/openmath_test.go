@@ -0,0 +1,74 @@
+package openmath
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/rotation"
+	"algex/terms"
+)
+
+func TestRoundTrip(t *testing.T) {
+	vs := []*terms.Exp{
+		terms.NewExp(),
+		terms.NewExp([]factor.Value{factor.D(7, 1)}),
+		terms.NewExp([]factor.Value{factor.S("x")}),
+		terms.NewExp([]factor.Value{factor.D(-1, 3), factor.S("x")}, []factor.Value{factor.Sp("y", -2), factor.S("z")}),
+	}
+	for i, v := range vs {
+		e, err := Unmarshal(Marshal(v))
+		if err != nil {
+			t.Fatalf("[%d] failed to unmarshal %q: %v", i, Marshal(v), err)
+		}
+		if got, want := e.String(), v.String(); got != want {
+			t.Errorf("[%d] got=%q want=%q", i, got, want)
+		}
+	}
+	r := rotation.RZ("t")
+	m, err := UnmarshalMatrix(MarshalMatrix(r))
+	if err != nil {
+		t.Fatalf("failed to unmarshal %q: %v", MarshalMatrix(r), err)
+	}
+	if got, want := m.String(), r.String(); got != want {
+		t.Errorf("matrix got=%q want=%q", got, want)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	vs := []struct {
+		x, s string
+	}{
+		{
+			x: `<OMOBJ><OMA><OMS cd="arith1" name="minus"/><OMA><OMS cd="arith1" name="power"/><OMA><OMS cd="arith1" name="plus"/><OMV name="a"/><OMV name="b"/></OMA><OMI>2</OMI></OMA><OMA><OMS cd="arith1" name="unary_minus"/><OMI>1</OMI></OMA></OMA></OMOBJ>`,
+			s: "1+2*a*b+a^2+b^2",
+		},
+		{
+			x: `<OMOBJ><OMA><OMS cd="arith1" name="divide"/><OMV name="a"/><OMA><OMS cd="arith1" name="times"/><OMI>2</OMI><OMV name="b"/></OMA></OMA></OMOBJ>`,
+			s: "1/2*a*b^-1",
+		},
+		{x: `<OMOBJ><OMF dec="1.5"/></OMOBJ>`},
+		{x: `<OMOBJ><OMA><OMS cd="transc1" name="sin"/><OMV name="x"/></OMA></OMOBJ>`},
+		{x: `<OMOBJ><OMA><OMS cd="arith1" name="divide"/><OMV name="a"/><OMA><OMS cd="arith1" name="plus"/><OMI>2</OMI><OMV name="b"/></OMA></OMA></OMOBJ>`},
+		{x: `<OMOBJ><OMA><OMS cd="arith1" name="power"/><OMV name="a"/><OMV name="b"/></OMA></OMOBJ>`},
+	}
+	for i, v := range vs {
+		e, err := Unmarshal([]byte(v.x))
+		if v.s == "" {
+			if err == nil {
+				t.Errorf("[%d] got=%q, want error", i, e)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] failed: %v", i, err)
+		} else if got := e.String(); got != v.s {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.s)
+		}
+	}
+	if _, err := UnmarshalMatrix([]byte(`<OMOBJ><OMV name="x"/></OMOBJ>`)); err == nil {
+		t.Error("expression accepted as a matrix")
+	}
+	if _, err := UnmarshalMatrix([]byte(`<OMOBJ><OMA><OMS cd="linalg2" name="matrix"/><OMA><OMS cd="linalg2" name="matrixrow"/><OMI>1</OMI></OMA><OMA><OMS cd="linalg2" name="matrixrow"/></OMA></OMA></OMOBJ>`)); err == nil {
+		t.Error("ragged matrix accepted")
+	}
+}
@@ -0,0 +1,160 @@
+package pattern
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"algex/terms"
+)
+
+// RuleSet is an ordered list of rules. Earlier rules take priority.
+//
+// A rule file holds one rule, "from -> to", per line. The variables of
+// the rules are declared by a line "vars x, y, ..." which applies to
+// the rules that follow it until the next such line; a line containing
+// "->" is always a rule. Each rule uses those of the variables that
+// appear in its pattern, and every declared variable must be used by
+// one of the rules. Text following a '#' is a comment and blank lines
+// are ignored. For example:
+//
+//	# Pythagorean identity.
+//	vars θ
+//	sθ^2 + cθ^2 -> 1
+type RuleSet struct {
+	Rules []*Rule
+}
+
+// ParseRules reads a rule file from r. The name of the file is used to
+// locate errors, all of which are reported.
+func ParseRules(r io.Reader, name string) (*RuleSet, error) {
+	s := &RuleSet{}
+	var vars []string
+	var errs []error
+	// used records the variables of the last vars line, declared at
+	// line at, that the following rules use.
+	used, at := map[string]bool{}, 0
+	unused := func() {
+		for _, v := range vars {
+			if !used[v] {
+				errs = append(errs, fmt.Errorf("%s:%d: variable %q is not used", name, at, v))
+			}
+		}
+	}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if f := strings.Fields(line); f[0] == "vars" && !strings.Contains(line, "->") {
+			unused()
+			vars = strings.FieldsFunc(strings.TrimPrefix(line, "vars"), func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})
+			used, at = map[string]bool{}, n
+			for _, v := range vars {
+				if err := validVar(v); err != nil {
+					errs = append(errs, fmt.Errorf("%s:%d: %v", name, n, err))
+					used[v] = true
+				}
+			}
+			continue
+		}
+		from, to, ok := strings.Cut(line, "->")
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s:%d: expected \"from -> to\"", name, n))
+			continue
+		}
+		p, err := compile(strings.TrimSpace(from), vars)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %v", name, n, err))
+			continue
+		}
+		for _, v := range p.vars {
+			used[v] = true
+		}
+		rule, err := newRule(p, strings.TrimSpace(to))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %v", name, n, err))
+			continue
+		}
+		rule.Source = fmt.Sprintf("%s:%d", name, n)
+		s.Rules = append(s.Rules, rule)
+	}
+	unused()
+	if err := sc.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %v", name, err))
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return s, nil
+}
+
+// LoadRules reads the rule file at path.
+func LoadRules(path string) (*RuleSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRules(f, path)
+}
+
+// Fired reports the number of times a rule rewrote an expression.
+type Fired struct {
+	Rule  *Rule
+	Count int
+}
+
+// Apply rewrites e with the rules of s until none match, each time
+// rewriting with the first rule that matches and changes e. It reports the rules
+// that fired in the order of s. Like Rule.Apply, it fails with an
+// error wrapping terms.ErrNonTerminating if an expression recurs or
+// there are too many rewrites.
+func (s *RuleSet) Apply(e *terms.Exp) (*terms.Exp, []Fired, error) {
+	counts := make([]int, len(s.Rules))
+	report := func() []Fired {
+		var fs []Fired
+		for i, n := range counts {
+			if n != 0 {
+				fs = append(fs, Fired{Rule: s.Rules[i], Count: n})
+			}
+		}
+		return fs
+	}
+	seen := map[string]bool{e.String(): true}
+	for n := 0; n < maxRewrites; n++ {
+		var m *Match
+		for i, r := range s.Rules {
+			f, hit, err := r.Rewrite(e)
+			if err != nil {
+				return nil, report(), fmt.Errorf("%s: %w", r.where(), err)
+			}
+			x := f.String()
+			if hit == nil || x == e.String() {
+				// No match, or no progress.
+				continue
+			}
+			counts[i]++
+			if seen[x] {
+				return nil, report(), fmt.Errorf("%w: %s: %q recurs", terms.ErrNonTerminating, r.where(), x)
+			}
+			seen[x] = true
+			e, m = f, hit
+			break
+		}
+		if m == nil {
+			return e, report(), nil
+		}
+	}
+	return nil, report(), fmt.Errorf("%w: more than %d rewrites", terms.ErrNonTerminating, maxRewrites)
+}
@@ -0,0 +1,141 @@
+package pattern
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"algex/rotation"
+	"algex/syntax"
+	"algex/terms"
+)
+
+func TestParseRules(t *testing.T) {
+	s, err := ParseRules(strings.NewReader(`
+# Comment.
+x -> y  # Trailing comment.
+vars n, m
+x^n*y^m -> z^n*w^m
+vars
+a^2 -> b
+vars -> 1
+`), "test.rules")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	var got []string
+	for _, r := range s.Rules {
+		got = append(got, fmt.Sprintf("%s: %v %v", r.Source, r, r.From.Vars()))
+	}
+	if want := "test.rules:3: x -> y []\ntest.rules:5: x^n*y^m -> z^n*w^m [m n]\ntest.rules:7: a^2 -> b []\ntest.rules:8: vars -> 1 []"; strings.Join(got, "\n") != want {
+		t.Errorf("got=%q want=%q", strings.Join(got, "\n"), want)
+	}
+
+	_, err = ParseRules(strings.NewReader("x -> y\nx + y\nvars n\nx^n -> (y\nx^n -> y\n(x -> y\nx^n -> ٣\nvars a, 1b\n"), "bad.rules")
+	if err == nil {
+		t.Fatal("parsed bad rules")
+	}
+	for _, want := range []string{"bad.rules:2:", "bad.rules:4:", "bad.rules:6:", "bad.rules:7:", "bad.rules:8:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "bad.rules:5:") {
+		t.Errorf("error %q reports a good rule", err)
+	}
+}
+
+func TestParseRulesVars(t *testing.T) {
+	// Each rule takes the variables of the block that it uses.
+	s, err := ParseRules(strings.NewReader("vars θ, φ\nsθ^2 + cθ^2 -> 1\nsφ*cφ -> 1/2*s2φ\n"), "test.rules")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	var got []string
+	for _, r := range s.Rules {
+		got = append(got, fmt.Sprint(r.From.Vars()))
+	}
+	if want := "[θ] [φ]"; strings.Join(got, " ") != want {
+		t.Errorf("got vars=%q want=%q", strings.Join(got, " "), want)
+	}
+	e, _ := syntax.Algex.Parse("sa^2+ca^2+sb*cb")
+	if x, _, err := s.Apply(e); err != nil || x.String() != "1+1/2*s2b" {
+		t.Errorf("got=%v (%v) want=1+1/2*s2b", x, err)
+	}
+
+	// A variable that no rule of its block uses is reported at its
+	// declaration.
+	_, err = ParseRules(strings.NewReader("vars θ, φ\nsθ^2 + cθ^2 -> 1\nvars ψ\nsψ -> tψ\n"), "unused.rules")
+	if err == nil || err.Error() != `unused.rules:1: variable "φ" is not used` {
+		t.Errorf("got err=%v, want φ unused at line 1", err)
+	}
+}
+
+func TestTrigRules(t *testing.T) {
+	s, err := LoadRules("testdata/trig.rules")
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	r2 := rotation.RX("t").Mx(rotation.RX("t"))
+	want := rotation.RX("2t")
+	rows, cols := r2.Dims()
+	fired := make(map[string]int)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			e, _ := r2.El(r, c)
+			got, fs, err := s.Apply(e)
+			if err != nil {
+				t.Fatalf("(%d,%d) apply failed: %v", r, c, err)
+			}
+			if w, _ := want.El(r, c); got.String() != w.String() {
+				t.Errorf("(%d,%d) got=%v want=%v", r, c, got, w)
+			}
+			for _, f := range fs {
+				fired[f.Rule.Source] += f.Count
+			}
+		}
+	}
+	if got, want := fmt.Sprint(fired), "map[testdata/trig.rules:7:2 testdata/trig.rules:8:2]"; got != want {
+		t.Errorf("fired got=%s want=%s", got, want)
+	}
+
+	e, _ := syntax.Algex.Parse("ca^4+2*ca^2*sa^2+sa^4+x")
+	got, fs, err := s.Apply(e)
+	if err != nil || got.String() != "1+x" || len(fs) != 1 || fs[0].Count != 3 {
+		t.Errorf("got=%v %v (%v) want=1+x", got, fs, err)
+	}
+}
+
+func TestApplyNoProgress(t *testing.T) {
+	s, err := ParseRules(strings.NewReader("vars n\nx^n*y^n -> (x*y)^n\nvars\nx^3 -> z\n"), "test.rules")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	e, _ := syntax.Algex.Parse("x^3*y^3")
+	got, fs, err := s.Apply(e)
+	if err != nil || got.String() != "y^3*z" {
+		t.Errorf("got=%v (%v) want=y^3*z", got, err)
+	}
+	if len(fs) != 1 || fs[0].Rule != s.Rules[1] || fs[0].Count != 1 {
+		t.Errorf("got fired=%v", fs)
+	}
+}
+
+func TestApplyLoop(t *testing.T) {
+	s, err := ParseRules(strings.NewReader("x -> y\ny -> x\n"), "loop.rules")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	e, _ := syntax.Algex.Parse("x")
+	_, fs, err := s.Apply(e)
+	if !errors.Is(err, terms.ErrNonTerminating) {
+		t.Errorf("got err=%v want %v", err, terms.ErrNonTerminating)
+	}
+	if !strings.Contains(err.Error(), "loop.rules:2: y -> x") {
+		t.Errorf("error %q does not locate the rule", err)
+	}
+	if len(fs) != 2 {
+		t.Errorf("got fired=%v", fs)
+	}
+}
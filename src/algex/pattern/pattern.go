@@ -0,0 +1,498 @@
+// Package pattern matches and rewrites expressions with patterns that
+// contain variables.
+//
+// A pattern is written as a sum of terms in the algex syntax, without
+// parentheses, and is compiled with a list of variable names. A
+// variable stands for either a fragment of a symbol name or an integer
+// exponent. For example, with the variable θ the pattern
+//
+//	sθ^2 + cθ^2
+//
+// matches st^2+ct^2 and sa^2+ca^2, binding θ to t and to a, and with
+// the variable n the pattern x^n*y^n matches x^3*y^3, binding n to 3.
+//
+// A pattern of several terms matches the terms of an expression that
+// share a common cofactor, so sθ^2+cθ^2 matches 2*a*st^2+3*a*ct^2+b
+// with the cofactor 2*a, leaving a*ct^2+b when the match is removed.
+package pattern
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"algex/factor"
+	"algex/syntax"
+	"algex/terms"
+)
+
+// Bindings holds the text bound to each variable of a match: a symbol
+// name fragment or a decimal exponent.
+type Bindings map[string]string
+
+// with returns a copy of b with v bound to x.
+func (b Bindings) with(v, x string) Bindings {
+	c := make(Bindings, len(b)+1)
+	for k, y := range b {
+		c[k] = y
+	}
+	c[v] = x
+	return c
+}
+
+// part is a literal fragment of a symbol name or, when v is set, a
+// variable.
+type part struct {
+	lit, v string
+}
+
+// pfactor is a factor of a pattern term. Its exponent is pow unless
+// the variable v is set.
+type pfactor struct {
+	sym []part
+	pow int
+	v   string
+}
+
+// pterm is a term of a pattern.
+type pterm struct {
+	coeff *big.Rat
+	fs    []pfactor
+}
+
+// Pattern is a compiled pattern.
+type Pattern struct {
+	text  string
+	vars  []string
+	terms []pterm
+	// kind records whether each variable is used as a "symbol" name
+	// fragment or an "exponent".
+	kind map[string]string
+}
+
+// Match describes where a pattern matched an expression.
+type Match struct {
+	// Bindings holds the value of each variable of the pattern.
+	Bindings Bindings
+	// Factor is the cofactor that multiplies the instantiated
+	// pattern to give Terms.
+	Factor *terms.Exp
+	// Terms is the part of the expression that matched, which
+	// rewriting replaces.
+	Terms *terms.Exp
+}
+
+// identRune indicates that r may appear in a symbol name, with first
+// indicating the first rune of the name.
+func identRune(r rune, first bool) bool {
+	return unicode.IsLetter(r) || r == '_' || (!first && unicode.IsDigit(r))
+}
+
+// validVar confirms that v can name a variable.
+func validVar(v string) error {
+	if v == "" {
+		return fmt.Errorf("empty variable name")
+	}
+	for i, r := range v {
+		if !identRune(r, i == 0) {
+			return fmt.Errorf("variable %q is not a symbol name", v)
+		}
+	}
+	return nil
+}
+
+// Compile compiles the pattern s with the variables vars, each of
+// which must be used.
+func Compile(s string, vars ...string) (*Pattern, error) {
+	p, err := compile(s, vars)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vars {
+		if _, ok := p.kind[v]; !ok {
+			return nil, fmt.Errorf("pattern %q: variable %q is not used", s, v)
+		}
+	}
+	return p, nil
+}
+
+// compile compiles the pattern s with the variables vars, dropping any
+// that are not used.
+func compile(s string, vars []string) (*Pattern, error) {
+	p := &Pattern{text: s}
+	for _, v := range vars {
+		if err := validVar(v); err != nil {
+			return nil, err
+		}
+		p.vars = append(p.vars, v)
+	}
+	// Prefer the longest variable name that matches.
+	sort.Slice(p.vars, func(i, j int) bool {
+		if len(p.vars[i]) != len(p.vars[j]) {
+			return len(p.vars[i]) > len(p.vars[j])
+		}
+		return p.vars[i] < p.vars[j]
+	})
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("pattern %q: %v", s, err)
+	}
+	vs := p.vars[:0]
+	for _, v := range p.vars {
+		if _, ok := p.kind[v]; ok {
+			vs = append(vs, v)
+		}
+	}
+	p.vars = vs
+	return p, nil
+}
+
+// String returns the text of the pattern.
+func (p *Pattern) String() string {
+	return p.text
+}
+
+// Vars returns the variables of the pattern.
+func (p *Pattern) Vars() []string {
+	return append([]string(nil), p.vars...)
+}
+
+// split divides a symbol name into literal fragments and variables.
+func (p *Pattern) split(sym string) []part {
+	var ps []part
+	lit := ""
+	for i := 0; i < len(sym); {
+		v := ""
+		for _, x := range p.vars {
+			if strings.HasPrefix(sym[i:], x) {
+				v = x
+				break
+			}
+		}
+		if v == "" {
+			_, n := utf8.DecodeRuneInString(sym[i:])
+			lit += sym[i : i+n]
+			i += n
+			continue
+		}
+		if lit != "" {
+			ps = append(ps, part{lit: lit})
+			lit = ""
+		}
+		ps = append(ps, part{v: v})
+		i += len(v)
+	}
+	if lit != "" {
+		ps = append(ps, part{lit: lit})
+	}
+	return ps
+}
+
+// isVar indicates that s is a variable of p.
+func (p *Pattern) isVar(s string) bool {
+	for _, v := range p.vars {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parse parses the text of p into its terms. Each variable may only be
+// used as an exponent or only in symbol names.
+func (p *Pattern) parse() error {
+	ts, err := syntax.Algex.Lex(p.text)
+	if err != nil {
+		return err
+	}
+	if len(ts) == 0 {
+		return fmt.Errorf("empty pattern")
+	}
+	at := func(i int) string {
+		if i < len(ts) {
+			return ts[i].Text
+		}
+		return ""
+	}
+	errorf := func(i int, format string, args ...interface{}) error {
+		if i >= len(ts) {
+			return fmt.Errorf("at end: "+format, args...)
+		}
+		return fmt.Errorf("at %d %q: "+format, append([]interface{}{ts[i].Pos, ts[i].Text}, args...)...)
+	}
+	used := make(map[string]string)
+	p.kind = used
+	use := func(v, how string) error {
+		if u, ok := used[v]; ok && u != how {
+			return fmt.Errorf("variable %q used as both %s and %s", v, u, how)
+		}
+		used[v] = how
+		return nil
+	}
+	for i := 0; i < len(ts); {
+		t := pterm{coeff: big.NewRat(1, 1)}
+		if at(i) == "+" || at(i) == "-" {
+			if at(i) == "-" {
+				t.coeff.Neg(t.coeff)
+			}
+			i++
+		} else if len(p.terms) != 0 {
+			return errorf(i, "expected \"+\" or \"-\"")
+		}
+		seen := make(map[string]bool)
+		for {
+			switch {
+			case i < len(ts) && ts[i].Num:
+				n, _ := new(big.Rat).SetString(ts[i].Text)
+				i++
+				if at(i) == "/" {
+					if i+1 >= len(ts) || !ts[i+1].Num {
+						return errorf(i+1, "expected a number")
+					}
+					d, _ := new(big.Rat).SetString(ts[i+1].Text)
+					if d.Sign() == 0 {
+						return errorf(i+1, "division by zero")
+					}
+					n.Quo(n, d)
+					i += 2
+				}
+				t.coeff.Mul(t.coeff, n)
+			case i < len(ts) && ts[i].Ident:
+				f := pfactor{sym: p.split(ts[i].Text), pow: 1}
+				if seen[ts[i].Text] {
+					return errorf(i, "repeated factor")
+				}
+				seen[ts[i].Text] = true
+				for _, x := range f.sym {
+					if x.v != "" {
+						if err := use(x.v, "symbol"); err != nil {
+							return errorf(i, "%v", err)
+						}
+					}
+				}
+				i++
+				if at(i) == "^" {
+					i++
+					sign := 1
+					if at(i) == "-" {
+						sign = -1
+						i++
+					}
+					switch {
+					case i < len(ts) && ts[i].Num:
+						n, err := strconv.Atoi(ts[i].Text)
+						if err != nil || n == 0 {
+							return errorf(i, "bad exponent")
+						}
+						f.pow = sign * n
+					case i < len(ts) && sign == 1 && p.isVar(ts[i].Text):
+						f.v = ts[i].Text
+						if err := use(f.v, "exponent"); err != nil {
+							return errorf(i, "%v", err)
+						}
+					default:
+						return errorf(i, "expected an exponent")
+					}
+					i++
+				}
+				t.fs = append(t.fs, f)
+			default:
+				return errorf(i, "expected a number or symbol")
+			}
+			if at(i) != "*" {
+				break
+			}
+			i++
+		}
+		if t.coeff.Sign() == 0 {
+			return fmt.Errorf("zero term")
+		}
+		p.terms = append(p.terms, t)
+	}
+	return nil
+}
+
+// matchSym matches the symbol name s against ps, calling fn with the
+// extended bindings for each way it matches until fn returns true.
+func matchSym(ps []part, s string, b Bindings, fn func(Bindings) bool) bool {
+	if len(ps) == 0 {
+		return s == "" && fn(b)
+	}
+	x := ps[0]
+	if x.v == "" {
+		return strings.HasPrefix(s, x.lit) && matchSym(ps[1:], s[len(x.lit):], b, fn)
+	}
+	if y, ok := b[x.v]; ok {
+		return strings.HasPrefix(s, y) && matchSym(ps[1:], s[len(y):], b, fn)
+	}
+	for i := 1; i <= len(s); i++ {
+		if i < len(s) && !utf8.RuneStart(s[i]) {
+			continue
+		}
+		if matchSym(ps[1:], s[i:], b.with(x.v, s[:i]), fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// match matches the pattern factors ps against the factors fs of a
+// term, whose remaining powers are held in pow. Each factor of fs is
+// matched at most once, and a literal exponent matches any power of
+// the same sign that is at least as large. It calls fn with the
+// extended bindings and the unmatched factors for each way the
+// factors match until fn returns true.
+func match(ps []pfactor, fs []factor.Value, pow []int, used []bool, b Bindings, fn func(Bindings, []factor.Value) bool) bool {
+	if len(ps) == 0 {
+		var rest []factor.Value
+		for j, f := range fs {
+			if pow[j] != 0 {
+				rest = append(rest, factor.Sp(f.Sym(), pow[j]))
+			}
+		}
+		return fn(b, rest)
+	}
+	x := ps[0]
+	for j, f := range fs {
+		if used[j] {
+			continue
+		}
+		q := f.Pow()
+		if matchSym(x.sym, f.Sym(), b, func(b Bindings) bool {
+			r := 0
+			switch {
+			case x.v == "":
+				if q*x.pow < 0 || q/x.pow < 1 {
+					return false
+				}
+				r = q - x.pow
+			case b[x.v] == "":
+				b = b.with(x.v, strconv.Itoa(q))
+			case b[x.v] != strconv.Itoa(q):
+				return false
+			}
+			used[j], pow[j] = true, r
+			ok := match(ps[1:], fs, pow, used, b, fn)
+			used[j], pow[j] = false, q
+			return ok
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTerm matches the pattern term t against the term u, a
+// coefficient followed by factors, calling fn with the extended
+// bindings, the ratio of the coefficients and the remaining factors
+// for each way it matches until fn returns true.
+func matchTerm(t pterm, u []factor.Value, b Bindings, fn func(Bindings, *big.Rat, []factor.Value) bool) bool {
+	fs := u[1:]
+	pow := make([]int, len(fs))
+	for j, f := range fs {
+		pow[j] = f.Pow()
+	}
+	c := new(big.Rat).Quo(u[0].Num(), t.coeff)
+	return match(t.fs, fs, pow, make([]bool, len(fs)), b, func(b Bindings, rest []factor.Value) bool {
+		return fn(b, c, rest)
+	})
+}
+
+// Match finds the first match of p in e. Terms of e are considered in
+// the order e.Terms returns them. The terms matched by each term of
+// the pattern must share the same factors once the pattern is removed,
+// and coefficients of the same sign. The coefficient of the cofactor
+// is the smallest ratio, so at least one matched term is used up
+// entirely.
+func (p *Pattern) Match(e *terms.Exp) (*Match, bool) {
+	ts := e.Terms()
+	used := make([]bool, len(ts))
+	var b Bindings
+	var c *big.Rat
+	var rest []factor.Value
+	var try func(i int, b0 Bindings, k string, c0 *big.Rat) bool
+	try = func(i int, b0 Bindings, k string, c0 *big.Rat) bool {
+		if i == len(p.terms) {
+			b, c = b0, c0
+			return true
+		}
+		for j, u := range ts {
+			if used[j] {
+				continue
+			}
+			if matchTerm(p.terms[i], u, b0, func(b1 Bindings, r *big.Rat, fs []factor.Value) bool {
+				x := terms.NewExp(fs).String()
+				c1 := r
+				if i != 0 {
+					if x != k || r.Sign() != c0.Sign() {
+						return false
+					}
+					if new(big.Rat).Abs(r).Cmp(new(big.Rat).Abs(c0)) > 0 {
+						c1 = c0
+					}
+				}
+				used[j] = true
+				defer func() { used[j] = false }()
+				if !try(i+1, b1, x, c1) {
+					return false
+				}
+				if i == 0 {
+					rest = fs
+				}
+				return true
+			}) {
+				return true
+			}
+		}
+		return false
+	}
+	if !try(0, Bindings{}, "", nil) {
+		return nil, false
+	}
+	f := terms.NewExp(append([]factor.Value{factor.R(c)}, rest...))
+	x, err := p.Expand(p.text, b)
+	if err != nil {
+		return nil, false
+	}
+	return &Match{Bindings: b, Factor: f, Terms: terms.Mul(f, x)}, true
+}
+
+// Expand substitutes the bindings b for the variables of p in the text
+// s and parses the result. Negative exponents are parenthesized.
+func (p *Pattern) Expand(s string, b Bindings) (*terms.Exp, error) {
+	ts, err := syntax.Algex.Lex(s)
+	if err != nil {
+		return nil, err
+	}
+	var out strings.Builder
+	for _, t := range ts {
+		out.WriteString(" ")
+		if !t.Ident {
+			out.WriteString(t.Text)
+			continue
+		}
+		for _, x := range p.split(t.Text) {
+			if x.v == "" {
+				out.WriteString(x.lit)
+				continue
+			}
+			y, ok := b[x.v]
+			if !ok {
+				return nil, fmt.Errorf("variable %q is not bound", x.v)
+			}
+			if strings.HasPrefix(y, "-") {
+				y = "(" + y + ")"
+			}
+			out.WriteString(y)
+		}
+	}
+	text := strings.TrimSpace(out.String())
+	e, err := syntax.Algex.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %v", text, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,121 @@
+package pattern
+
+import (
+	"fmt"
+	"testing"
+
+	"algex/syntax"
+)
+
+func TestCompile(t *testing.T) {
+	for i, s := range []struct {
+		p    string
+		vars []string
+	}{
+		{p: ""},
+		{p: "x +"},
+		{p: "x y"},
+		{p: "x^0"},
+		{p: "x^y"},
+		{p: "x*x"},
+		{p: "(x)"},
+		{p: "0*x"},
+		{p: "x^n*n", vars: []string{"n"}},
+		{p: "x", vars: []string{"n"}},
+		{p: "x", vars: []string{"1n"}},
+		{p: "x^-n", vars: []string{"n"}},
+		{p: "x + ٣"},
+		{p: "x + (y)"},
+	} {
+		if p, err := Compile(s.p, s.vars...); err == nil {
+			t.Errorf("[%d] %q %v compiled: %v", i, s.p, s.vars, p)
+		}
+	}
+	p, err := Compile("-1/2*sθ^2*x + 3*cθ^-1", "θ")
+	if err != nil {
+		t.Fatalf("failed to compile: %v", err)
+	}
+	e, _ := syntax.Algex.Parse("-1/2*st^2*x+3*ct^-1")
+	if m, ok := p.Match(e); !ok || m.Factor.String() != "1" {
+		t.Errorf("%v did not match %v: %v", p, e, m)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	vs := []struct {
+		p      string
+		vars   []string
+		e      string
+		ok     bool
+		b      string
+		factor string
+		terms  string
+	}{
+		{p: "sθ^2 + cθ^2", vars: []string{"θ"}, e: "2*a*st^2+2*a*ct^2+b", ok: true,
+			b: "map[θ:t]", factor: "2*a", terms: "2*a*ct^2+2*a*st^2"},
+		{p: "sθ^2 + cθ^2", vars: []string{"θ"}, e: "ca^2+st^2", ok: false},
+		{p: "sθ^2 + cθ^2", vars: []string{"θ"}, e: "3*a*st^2+2*a*ct^2", ok: true,
+			b: "map[θ:t]", factor: "2*a", terms: "2*a*ct^2+2*a*st^2"},
+		{p: "sθ^2 + cθ^2", vars: []string{"θ"}, e: "3*a*st^2-2*a*ct^2", ok: false},
+		{p: "sθ^2 + cθ^2", vars: []string{"θ"}, e: "ca^2+sa^2*x+st^2+x*ca^2", ok: true,
+			b: "map[θ:a]", factor: "x", terms: "ca^2*x+sa^2*x"},
+		{p: "sθ^2 + cθ^2", vars: []string{"θ"}, e: "sa^4+ca^2*sa^2", ok: true,
+			b: "map[θ:a]", factor: "sa^2", terms: "ca^2*sa^2+sa^4"},
+		{p: "x^n*y^n", vars: []string{"n"}, e: "3*x^3*y^3*z", ok: true,
+			b: "map[n:3]", factor: "3*z", terms: "3*x^3*y^3*z"},
+		{p: "x^n*y^n", vars: []string{"n"}, e: "x^2*y^3", ok: false},
+		{p: "x^n*y^n", vars: []string{"n"}, e: "x^-1*y^-1", ok: true,
+			b: "map[n:-1]", factor: "1", terms: "x^-1*y^-1"},
+		{p: "2*x^2", e: "x^5", ok: true, b: "map[]", factor: "1/2*x^3", terms: "x^5"},
+		{p: "x^2", e: "x^-2", ok: false},
+		{p: "aα*bβ", vars: []string{"α", "β"}, e: "a1*b2", ok: true,
+			b: "map[α:1 β:2]", factor: "1", terms: "a1*b2"},
+		{p: "cθ*sθ", vars: []string{"θ"}, e: "cab*sa", ok: false},
+		{p: "x - y", e: "x+y", ok: false},
+		{p: "x - y", e: "-2*x+2*y", ok: true, b: "map[]", factor: "-2", terms: "-2*x+2*y"},
+	}
+	for i, v := range vs {
+		p, err := Compile(v.p, v.vars...)
+		if err != nil {
+			t.Fatalf("[%d] failed to compile %q: %v", i, v.p, err)
+		}
+		e, err := syntax.Algex.Parse(v.e)
+		if err != nil {
+			t.Fatalf("[%d] failed to parse %q: %v", i, v.e, err)
+		}
+		m, ok := p.Match(e)
+		if ok != v.ok {
+			t.Errorf("[%d] %q in %q got=%v want=%v", i, v.p, v.e, ok, v.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got := fmt.Sprint(m.Bindings); got != v.b {
+			t.Errorf("[%d] %q in %q bindings got=%s want=%s", i, v.p, v.e, got, v.b)
+		}
+		if got := m.Factor.String(); got != v.factor {
+			t.Errorf("[%d] %q in %q factor got=%s want=%s", i, v.p, v.e, got, v.factor)
+		}
+		if got := m.Terms.String(); got != v.terms {
+			t.Errorf("[%d] %q in %q terms got=%s want=%s", i, v.p, v.e, got, v.terms)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	p, err := Compile("sθ^n", "θ", "n")
+	if err != nil {
+		t.Fatalf("failed to compile: %v", err)
+	}
+	e, err := p.Expand("(cθ*x)^n + θ", Bindings{"θ": "t", "n": "-2"})
+	if err != nil {
+		t.Fatalf("failed to expand: %v", err)
+	}
+	if got, want := e.String(), "ct^-2*x^-2+t"; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	if _, err := p.Expand("θ", Bindings{}); err == nil {
+		t.Error("expanded unbound variable")
+	}
+}
@@ -0,0 +1,96 @@
+package pattern
+
+import (
+	"fmt"
+
+	"algex/terms"
+)
+
+// maxRewrites bounds the number of times Apply rewrites an expression.
+const maxRewrites = 1 << 12
+
+// Rule rewrites matches of a pattern with an expression written, in
+// the algex syntax, in terms of the variables of the pattern.
+type Rule struct {
+	From *Pattern
+	To   string
+	// Source, when set, locates the definition of the rule as
+	// "file:line".
+	Source string
+}
+
+// NewRule compiles a rule that rewrites from with to, where both use
+// the variables vars. It fails unless to parses for any bindings.
+func NewRule(from, to string, vars ...string) (*Rule, error) {
+	p, err := Compile(from, vars...)
+	if err != nil {
+		return nil, err
+	}
+	return newRule(p, to)
+}
+
+// newRule creates a rule rewriting matches of p with to.
+func newRule(p *Pattern, to string) (*Rule, error) {
+	b := Bindings{}
+	for v, k := range p.kind {
+		if b[v] = "x"; k == "exponent" {
+			b[v] = "1"
+		}
+	}
+	if _, err := p.Expand(to, b); err != nil {
+		return nil, fmt.Errorf("replacement %q: %v", to, err)
+	}
+	return &Rule{From: p, To: to}, nil
+}
+
+// String represents a rule as "from -> to".
+func (r *Rule) String() string {
+	return fmt.Sprintf("%s -> %s", r.From, r.To)
+}
+
+// where describes a rule in errors, prefixed by its source if known.
+func (r *Rule) where() string {
+	if r.Source == "" {
+		return r.String()
+	}
+	return r.Source + ": " + r.String()
+}
+
+// Rewrite replaces the first match of the rule in e. It reports the
+// match, or nil if there is none.
+func (r *Rule) Rewrite(e *terms.Exp) (*terms.Exp, *Match, error) {
+	m, ok := r.From.Match(e)
+	if !ok {
+		return e, nil, nil
+	}
+	to, err := r.From.Expand(r.To, m.Bindings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return terms.Add(terms.Sub(e, m.Terms), terms.Mul(m.Factor, to)), m, nil
+}
+
+// Apply rewrites matches of the rule in e until none remain, or a
+// rewrite leaves e unchanged, and reports the number of rewrites. It
+// fails with an error wrapping terms.ErrNonTerminating if an expression
+// recurs or there are too many rewrites.
+func (r *Rule) Apply(e *terms.Exp) (*terms.Exp, int, error) {
+	seen := map[string]bool{e.String(): true}
+	for n := 0; n < maxRewrites; n++ {
+		f, m, err := r.Rewrite(e)
+		if err != nil || m == nil {
+			return f, n, err
+		}
+		s := f.String()
+		if s == e.String() {
+			// The replacement is the same as the match.
+			return e, n, nil
+		}
+		if seen[s] {
+			return nil, n, fmt.Errorf("%w: %s: %q recurs", terms.ErrNonTerminating, r.where(), s)
+		}
+		seen[s] = true
+		e = f
+	}
+	return nil, maxRewrites, fmt.Errorf("%w: %s: more than %d rewrites", terms.ErrNonTerminating, r.where(), maxRewrites)
+}
@@ -0,0 +1,82 @@
+package pattern
+
+import (
+	"errors"
+	"testing"
+
+	"algex/syntax"
+	"algex/terms"
+)
+
+func TestNewRule(t *testing.T) {
+	if _, err := NewRule("x^n", "(x", "n"); err == nil {
+		t.Error("accepted bad replacement")
+	}
+	if _, err := NewRule("x^n", "y^m", "n"); err == nil {
+		t.Error("accepted unknown symbol syntax")
+	}
+	r, err := NewRule("x^n*y^n", "(x*y)^n", "n")
+	if err != nil {
+		t.Fatalf("failed to compile rule: %v", err)
+	}
+	if got, want := r.String(), "x^n*y^n -> (x*y)^n"; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	if _, err := NewRule("x^n", "٣", "n"); err == nil {
+		t.Error("accepted a non-ASCII digit")
+	}
+}
+
+func TestApply(t *testing.T) {
+	vs := []struct {
+		from, to string
+		vars     []string
+		e, want  string
+		n        int
+	}{
+		{from: "sθ^2 + cθ^2", to: "1", vars: []string{"θ"},
+			e: "ca^2*x+sa^2*x+ct^4+2*ct^2*st^2+st^4", want: "1+x", n: 4},
+		{from: "sθ*cθ", to: "1/2*s2θ", vars: []string{"θ"},
+			e: "4*ca*sa*ct*st", want: "s2a*s2t", n: 2},
+		{from: "x^n*y^n", to: "z^n", vars: []string{"n"},
+			e: "x^2*y^2+x*y^3", want: "x*y^3+z^2", n: 1},
+	}
+	for i, v := range vs {
+		r, err := NewRule(v.from, v.to, v.vars...)
+		if err != nil {
+			t.Fatalf("[%d] failed to compile rule: %v", i, err)
+		}
+		e, err := syntax.Algex.Parse(v.e)
+		if err != nil {
+			t.Fatalf("[%d] failed to parse %q: %v", i, v.e, err)
+		}
+		got, n, err := r.Apply(e)
+		if err != nil {
+			t.Errorf("[%d] %v on %q failed: %v", i, r, v.e, err)
+			continue
+		}
+		if got.String() != v.want || n != v.n {
+			t.Errorf("[%d] %v on %q got=%q (%d) want=%q (%d)", i, r, v.e, got, n, v.want, v.n)
+		}
+	}
+
+	// The replacement canonicalizes to the match, so there is no
+	// further progress to make.
+	r, err := NewRule("x^n*y^n", "(x*y)^n", "n")
+	if err != nil {
+		t.Fatalf("failed to compile rule: %v", err)
+	}
+	e, _ := syntax.Algex.Parse("x^3*y^3+x")
+	if got, n, err := r.Apply(e); err != nil || n != 0 || got.String() != e.String() {
+		t.Errorf("%v on %v got=%v (%d, %v) want unchanged", r, e, got, n, err)
+	}
+
+	r, err = NewRule("x", "x + y")
+	if err != nil {
+		t.Fatalf("failed to compile rule: %v", err)
+	}
+	x, _ := syntax.Algex.Parse("x")
+	if _, _, err := r.Apply(x); !errors.Is(err, terms.ErrNonTerminating) {
+		t.Errorf("x -> x+y got err=%v want %v", err, terms.ErrNonTerminating)
+	}
+}
@@ -5,6 +5,8 @@
 package rotation
 
 import (
+	"fmt"
+
 	"algex/factor"
 	"algex/matrix"
 	"algex/terms"
@@ -54,3 +56,24 @@ func RZ(theta string) *matrix.Matrix {
 	m.Set(2, 2, terms.NewExp(one))
 	return m
 }
+
+// Axis returns the axis of a 3D rotation matrix, m, as the column
+// vector (m32-m23, m13-m31, m21-m12). This is the eigenvector of m for
+// the eigenvalue 1, scaled by twice the sine of the rotation angle, so
+// it is zero for rotations by 0 or pi.
+func Axis(m *matrix.Matrix) (*matrix.Matrix, error) {
+	if rows, cols := m.Dims(); rows != 3 || cols != 3 {
+		return nil, fmt.Errorf("need a 3x3 rotation matrix, not %dx%d", rows, cols)
+	}
+	el := func(r, c int) *terms.Exp {
+		if e, _ := m.El(r, c); e != nil {
+			return e
+		}
+		return terms.NewExp()
+	}
+	v, _ := matrix.NewMatrix(3, 1)
+	for i, ij := range [][4]int{{2, 1, 1, 2}, {0, 2, 2, 0}, {1, 0, 0, 1}} {
+		v.Set(i, 0, terms.Sub(el(ij[0], ij[1]), el(ij[2], ij[3])))
+	}
+	return v, nil
+}
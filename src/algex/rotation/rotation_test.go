@@ -1,6 +1,7 @@
 package rotation
 
 import (
+	"fmt"
 	"testing"
 
 	"algex/factor"
@@ -8,27 +9,78 @@ import (
 	"algex/terms"
 )
 
-func TestR(t *testing.T) {
+func TestInverse(t *testing.T) {
+	b := []factor.Value{factor.Sp("st", 2)}
+	c := terms.NewExp([]factor.Value{factor.D(1, 1)}, []factor.Value{factor.D(-1, 1), factor.Sp("ct", 2)})
 	for i, r := range []*matrix.Matrix{RX("t"), RY("t"), RZ("t")} {
-		r2 := r.Mx(r)
-		ans := r2.Substitute(
-			[]factor.Value{factor.Sp("ct", 2)},
-			terms.NewExp([]factor.Value{factor.S("c2t")},
-				[]factor.Value{factor.Sp("st", 2)}),
-		).Substitute(
-			[]factor.Value{factor.S("ct"), factor.S("st")},
-			terms.NewExp([]factor.Value{factor.D(1, 2), factor.S("s2t")}),
-		)
-		// Should equal r if 2t -> t.
-		cf := r.Add(ans, terms.NewExp([]factor.Value{factor.D(-1, 1)})).Substitute(
-			[]factor.Value{factor.S("c2t")},
-			terms.NewExp([]factor.Value{factor.S("ct")}),
-		).Substitute(
-			[]factor.Value{factor.S("s2t")},
-			terms.NewExp([]factor.Value{factor.S("st")}),
-		)
-		if cf.String() != "[[0, 0, 0], [0, 0, 0], [0, 0, 0]]" {
-			t.Errorf("[%d] got=%v, want=zero", i, cf)
+		num, den, err := r.Inverse()
+		if err != nil {
+			t.Fatalf("[%d] inverse failed: %v", i, err)
 		}
+		// The determinant is ct^2+st^2 = 1, so the inverse is the
+		// adjugate, which is the transpose.
+		if got, err := terms.Substitute(den, b, c); err != nil || got.String() != "1" {
+			t.Errorf("[%d] den got=%v (%v) want=1", i, got, err)
+		}
+		if got, err := num.Substitute(b, c); err != nil || got.String() != r.Transpose().String() {
+			t.Errorf("[%d] num got=%v (%v) want=%v", i, got, err, r.Transpose())
+		}
+	}
+}
+
+func TestAxis(t *testing.T) {
+	vs := []struct {
+		r    *matrix.Matrix
+		want string
+	}{
+		{RX("t"), "[[2*st], [0], [0]]"},
+		{RY("t"), "[[0], [2*st], [0]]"},
+		{RZ("t"), "[[0], [0], [2*st]]"},
+	}
+	for i, v := range vs {
+		a, err := Axis(v.r)
+		if err != nil {
+			t.Fatalf("[%d] axis failed: %v", i, err)
+		}
+		if got := a.String(); got != v.want {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.want)
+		}
+	}
+
+	// The axis of a composite rotation is left unchanged by it.
+	r := RX("a").Mx(RZ("b"))
+	a, err := Axis(r)
+	if err != nil {
+		t.Fatalf("axis failed: %v", err)
+	}
+	d := r.Mx(a).Add(a, terms.NewExp([]factor.Value{factor.D(-1, 1)}))
+	for _, x := range []string{"a", "b"} {
+		if d, err = d.Substitute([]factor.Value{factor.Sp("s"+x, 2)},
+			terms.NewExp([]factor.Value{factor.D(1, 1)}, []factor.Value{factor.D(-1, 1), factor.Sp("c"+x, 2)})); err != nil {
+			t.Fatalf("substitute failed: %v", err)
+		}
+	}
+	if !d.IsZero() {
+		t.Errorf("R*axis-axis got=%v, want zero", d)
+	}
+	m, _ := RX("a").Submatrix(matrix.Range{From: 0, To: 2}, matrix.Range{From: 0, To: 2})
+	if _, err := Axis(m); err == nil {
+		t.Error("2x2 matrix accepted")
+	}
+}
+
+func TestEigen(t *testing.T) {
+	es, err := RZ("t").Eigen("x")
+	if err != nil {
+		t.Fatalf("eigen failed: %v", err)
+	}
+	if len(es.Values) != 1 || es.Values[0].Value.String() != "1" {
+		t.Fatalf("got eigenvalues %v, want 1", es.Values)
+	}
+	if got := fmt.Sprint(es.Values[0].Vectors); got != "[[[0], [0], [1]]]" {
+		t.Errorf("got eigenvectors %s, want the z-axis", got)
+	}
+	if got := fmt.Sprint(es.Factors); got != "[-2*ct*x+ct^2+st^2+x^2]" {
+		t.Errorf("got factors %s", got)
 	}
 }
@@ -0,0 +1,370 @@
+// Package server exposes algex operations as a local HTTP/JSON API.
+//
+// Operations are requested by POSTing a Request to /eval. Arguments
+// are written in the syntax of the String methods of terms.Exp and
+// matrix.Matrix, so matrices look like [[a, b], [c, d]]. The operations
+// are:
+//
+//	parse       [x]                  x in canonical form
+//	simplify    [x]                  the same as parse; expressions are
+//	                                 always held in simplified form
+//	add         [x, y, ...]          the sum of expressions or matrices
+//	sub         [x, y]               x - y
+//	mul         [x, y, ...]          the product of expressions or matrices
+//	substitute  [x, pattern, value]  x with the term pattern replaced by value
+//	identity    [n]                  the n x n identity matrix
+//	rx, ry, rz  [angle]              a rotation matrix for angle
+//
+// Each result is returned as text, LaTeX and JSON.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"algex/factor"
+	"algex/matrix"
+	"algex/rotation"
+	"algex/syntax"
+	"algex/terms"
+)
+
+// Request is the body of a request to /eval.
+type Request struct {
+	Op   string   `json:"op"`
+	Args []string `json:"args"`
+}
+
+// Response is the body of a reply from /eval. Either Error is set or
+// the result is given in each of the Text, LaTeX and JSON forms.
+type Response struct {
+	Text  string      `json:"text,omitempty"`
+	LaTeX string      `json:"latex,omitempty"`
+	JSON  interface{} `json:"json,omitempty"`
+	// Terms counts the terms of the result, summed over all of the
+	// elements of a matrix.
+	Terms int    `json:"terms"`
+	Error string `json:"error,omitempty"`
+}
+
+// maxBody limits the size of a request body.
+const maxBody = 1 << 20
+
+// maxDim limits the dimension of a matrix the server creates.
+const maxDim = 1 << 10
+
+// Server handles algex requests. The zero value imposes no limits.
+type Server struct {
+	// MaxTerms, when positive, bounds the number of terms permitted
+	// in arguments, results and any intermediate expression.
+	MaxTerms int
+	// MaxDegree and MaxCoeffBits, when positive, bound the powers of
+	// symbols and the bit lengths of coefficients computed.
+	MaxDegree, MaxCoeffBits int
+	// Timeout, when positive, bounds the time taken to compute a
+	// result. A computation that times out is interrupted.
+	Timeout time.Duration
+}
+
+// httpError is an error with an HTTP status.
+type httpError struct {
+	status int
+	err    error
+}
+
+// Error returns the text of the error.
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+// badRequest returns a formatted error with a Bad Request status.
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// tooBig returns a formatted error indicating that a limit was
+// exceeded.
+func tooBig(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusUnprocessableEntity, err: fmt.Errorf(format, args...)}
+}
+
+// value is either an expression or a matrix.
+type value struct {
+	e *terms.Exp
+	m *matrix.Matrix
+}
+
+// count returns the number of terms in v.
+func (v value) count() int {
+	if v.m == nil {
+		return len(v.e.Terms())
+	}
+	n := 0
+	rows, cols := v.m.Dims()
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			e, _ := v.m.El(r, c)
+			n += len(e.Terms())
+		}
+	}
+	return n
+}
+
+// ServeHTTP handles a request to /eval.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/eval" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		reply(w, http.StatusMethodNotAllowed, Response{Error: "POST required"})
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&req); err != nil {
+		reply(w, http.StatusBadRequest, Response{Error: "bad request: " + err.Error()})
+		return
+	}
+	v, err := s.eval(r.Context(), req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if h, ok := err.(*httpError); ok {
+			status = h.status
+		}
+		reply(w, status, Response{Error: err.Error()})
+		return
+	}
+	resp := Response{Terms: v.count()}
+	if v.m != nil {
+		resp.Text, resp.LaTeX, resp.JSON = v.m.String(), syntax.LaTeXMatrix(v.m), v.m
+	} else {
+		resp.Text, resp.LaTeX, resp.JSON = v.e.String(), syntax.LaTeX(v.e), v.e
+	}
+	reply(w, http.StatusOK, resp)
+}
+
+// reply writes a JSON response.
+func reply(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// eval parses the arguments of a request and computes its result
+// within the limits of s. Both parsing and computation are bounded,
+// since an argument such as (a+b)^1000 is itself expensive to parse.
+func (s *Server) eval(ctx context.Context, req Request) (value, error) {
+	ctx = terms.WithLimits(ctx, terms.Limits{
+		MaxTerms:     s.MaxTerms,
+		MaxDegree:    s.MaxDegree,
+		MaxCoeffBits: s.MaxCoeffBits,
+	})
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	v, err := s.parseAndApply(ctx, req)
+	switch {
+	case errors.Is(err, terms.ErrLimit), errors.Is(err, terms.ErrNonTerminating):
+		return value{}, tooBig("%s: %v", req.Op, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return value{}, &httpError{status: http.StatusServiceUnavailable, err: fmt.Errorf("%s timed out after %v", req.Op, s.Timeout)}
+	case errors.Is(err, context.Canceled):
+		return value{}, &httpError{status: http.StatusServiceUnavailable, err: fmt.Errorf("%s canceled", req.Op)}
+	case err != nil:
+		return value{}, err
+	}
+	return s.check(v)
+}
+
+// ops holds the number of arguments of each operation, or 0 for an
+// operation that takes one or more.
+var ops = map[string]int{
+	"parse":      1,
+	"simplify":   1,
+	"add":        0,
+	"sub":        2,
+	"mul":        0,
+	"substitute": 3,
+	"identity":   1,
+	"rx":         1,
+	"ry":         1,
+	"rz":         1,
+}
+
+// parseAndApply parses the arguments of a request and computes its
+// result under ctx. The operation is checked before any argument is
+// parsed.
+func (s *Server) parseAndApply(ctx context.Context, req Request) (value, error) {
+	n, ok := ops[req.Op]
+	if !ok {
+		return value{}, badRequest("unknown operation %q", req.Op)
+	} else if n != 0 && len(req.Args) != n {
+		return value{}, badRequest("%s needs %d arguments, not %d", req.Op, n, len(req.Args))
+	} else if len(req.Args) == 0 {
+		return value{}, badRequest("%s needs arguments", req.Op)
+	}
+	var args []value
+	for i, a := range req.Args {
+		var v value
+		var err error
+		if strings.HasPrefix(strings.TrimSpace(a), "[") {
+			v.m, err = syntax.Algex.ParseMatrixCtx(ctx, a)
+		} else {
+			v.e, err = syntax.Algex.ParseCtx(ctx, a)
+		}
+		if err != nil && (errors.Is(err, terms.ErrLimit) || ctx.Err() != nil) {
+			return value{}, fmt.Errorf("argument %d: %w", i, err)
+		} else if err != nil {
+			return value{}, badRequest("argument %d: %v", i, err)
+		}
+		if s.MaxTerms > 0 && v.count() > s.MaxTerms {
+			return value{}, tooBig("argument %d has more than %d terms", i, s.MaxTerms)
+		}
+		args = append(args, v)
+	}
+	return apply(ctx, req.Op, args, s.MaxTerms)
+}
+
+// check confirms that a result is within the term limit of s.
+func (s *Server) check(v value) (value, error) {
+	if s.MaxTerms > 0 && v.count() > s.MaxTerms {
+		return value{}, tooBig("result has more than %d terms", s.MaxTerms)
+	}
+	return v, nil
+}
+
+// one and minusOne are the expressions 1 and -1.
+var (
+	one      = terms.NewExp([]factor.Value{factor.D(1, 1)})
+	minusOne = terms.NewExp([]factor.Value{factor.D(-1, 1)})
+)
+
+// apply computes the result of an operation under ctx. Products of
+// expressions that would obviously exceed maxTerms, when it is
+// positive, are not attempted.
+func apply(ctx context.Context, op string, args []value, maxTerms int) (value, error) {
+	switch op {
+	case "parse", "simplify":
+		return args[0], nil
+	case "add":
+		if args[0].m == nil {
+			var es []*terms.Exp
+			for i, a := range args {
+				if a.e == nil {
+					return value{}, badRequest("argument %d: expression required", i)
+				}
+				es = append(es, a.e)
+			}
+			return value{e: terms.Add(es...)}, nil
+		}
+		m := args[0].m
+		for i, a := range args[1:] {
+			if a.m == nil {
+				return value{}, badRequest("argument %d: matrix required", i+1)
+			}
+			var err error
+			if m, err = m.Sum(a.m, one); err != nil {
+				return value{}, badRequest("argument %d: %v", i+1, err)
+			}
+		}
+		return value{m: m}, nil
+	case "sub":
+		switch {
+		case args[0].e != nil && args[1].e != nil:
+			return value{e: terms.Sub(args[0].e, args[1].e)}, nil
+		case args[0].m != nil && args[1].m != nil:
+			m, err := args[0].m.Sum(args[1].m, minusOne)
+			if err != nil {
+				return value{}, badRequest("%v", err)
+			}
+			return value{m: m}, nil
+		}
+		return value{}, badRequest("unable to subtract a matrix and an expression")
+	case "mul":
+		if args[0].m == nil {
+			es := []*terms.Exp{}
+			n := 1
+			for i, a := range args {
+				if a.e == nil {
+					return value{}, badRequest("argument %d: expression required", i)
+				}
+				if n *= len(a.e.Terms()); maxTerms > 0 && n > maxTerms {
+					return value{}, tooBig("product may have more than %d terms", maxTerms)
+				}
+				es = append(es, a.e)
+			}
+			e, err := terms.MulCtx(ctx, es...)
+			if err != nil {
+				return value{}, err
+			}
+			return value{e: e}, nil
+		}
+		m := args[0].m
+		for i, a := range args[1:] {
+			if a.m == nil {
+				return value{}, badRequest("argument %d: matrix required", i+1)
+			}
+			var err error
+			if m, err = m.MulCtx(ctx, a.m); errors.Is(err, matrix.ErrDimension) {
+				return value{}, badRequest("argument %d: %v", i+1, err)
+			} else if err != nil {
+				return value{}, err
+			}
+		}
+		return value{m: m}, nil
+	case "substitute":
+		pat := args[1].e.Terms()
+		if len(pat) != 1 {
+			return value{}, badRequest("argument 1: pattern must be a single term")
+		}
+		if args[2].e == nil {
+			return value{}, badRequest("argument 2: expression required")
+		}
+		var v value
+		var err error
+		if args[0].m != nil {
+			v.m, err = args[0].m.SubstituteCtx(ctx, pat[0], args[2].e)
+		} else {
+			v.e, err = terms.SubstituteCtx(ctx, args[0].e, pat[0], args[2].e)
+		}
+		return v, err
+	case "identity":
+		n, ok := args[0].e.AsNumber()
+		if args[0].e == nil || !ok || !n.IsInt() || !n.Num().IsInt64() {
+			return value{}, badRequest("argument 0: dimension required")
+		}
+		d := n.Num().Int64()
+		if d > maxDim {
+			return value{}, tooBig("argument 0: dimension exceeds %d", maxDim)
+		}
+		if maxTerms > 0 && d > int64(maxTerms) {
+			return value{}, tooBig("identity would have more than %d terms", maxTerms)
+		}
+		m, err := matrix.Identity(int(d))
+		if err != nil {
+			return value{}, badRequest("argument 0: %v", err)
+		}
+		return value{m: m}, nil
+	case "rx", "ry", "rz":
+		ts := args[0].e.Terms()
+		if len(ts) != 1 || len(ts[0]) != 2 || ts[0][0].Num().Cmp(big.NewRat(1, 1)) != 0 || ts[0][1].Pow() != 1 {
+			return value{}, badRequest("argument 0: angle name required")
+		}
+		r := map[string]func(string) *matrix.Matrix{
+			"rx": rotation.RX,
+			"ry": rotation.RY,
+			"rz": rotation.RZ,
+		}[op]
+		return value{m: r(ts[0][1].Sym())}, nil
+	}
+	return value{}, badRequest("unknown operation %q", op)
+}
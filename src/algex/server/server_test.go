@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// post sends a request to s and decodes the response.
+func post(t *testing.T, s *Server, body string) (int, Response) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/eval", bytes.NewBufferString(body)))
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("bad response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func TestEval(t *testing.T) {
+	s := &Server{}
+	vs := []struct {
+		req, text string
+		terms     int
+	}{
+		{req: `{"op": "parse", "args": ["(a+b)^2"]}`, text: "2*a*b+a^2+b^2", terms: 3},
+		{req: `{"op": "simplify", "args": ["a*b/a"]}`, text: "b", terms: 1},
+		{req: `{"op": "add", "args": ["a", "b", "-a"]}`, text: "b", terms: 1},
+		{req: `{"op": "sub", "args": ["a", "b"]}`, text: "a-b", terms: 2},
+		{req: `{"op": "mul", "args": ["a+b", "a-b"]}`, text: "a^2-b^2", terms: 2},
+		{req: `{"op": "substitute", "args": ["x^2", "x", "y+1"]}`, text: "1+2*y+y^2", terms: 3},
+		{req: `{"op": "identity", "args": ["2"]}`, text: "[[1, 0], [0, 1]]", terms: 2},
+		{req: `{"op": "rz", "args": ["t"]}`, text: "[[ct, -st, 0], [st, ct, 0], [0, 0, 1]]", terms: 5},
+		{req: `{"op": "mul", "args": ["[[1, a], [0, 1]]", "[[1, b], [0, 1]]"]}`, text: "[[1, a+b], [0, 1]]", terms: 4},
+		{req: `{"op": "sub", "args": ["[[1, a]]", "[[1, b]]"]}`, text: "[[0, a-b]]", terms: 2},
+	}
+	for i, v := range vs {
+		code, resp := post(t, s, v.req)
+		if code != http.StatusOK {
+			t.Errorf("[%d] %s: status=%d error=%q", i, v.req, code, resp.Error)
+			continue
+		}
+		if resp.Text != v.text || resp.Terms != v.terms {
+			t.Errorf("[%d] %s: got=%q (%d terms) want=%q (%d terms)", i, v.req, resp.Text, resp.Terms, v.text, v.terms)
+		}
+		if resp.LaTeX == "" || resp.JSON == nil {
+			t.Errorf("[%d] %s: missing latex or json forms: %+v", i, v.req, resp)
+		}
+	}
+	_, resp := post(t, s, `{"op": "parse", "args": ["-x/3"]}`)
+	if want := `\frac{1}{3} x`; resp.LaTeX != "-"+want {
+		t.Errorf("latex got=%q want=%q", resp.LaTeX, "-"+want)
+	}
+	if b, _ := json.Marshal(resp.JSON); string(b) != `[{"coeff":"-1/3","factors":[{"pow":1,"sym":"x"}]}]` {
+		t.Errorf("json got=%s", b)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := &Server{MaxTerms: 4}
+	vs := []struct {
+		req  string
+		code int
+	}{
+		{req: `{"op": "parse", "args": ["a+"]}`, code: http.StatusBadRequest},
+		{req: `{"op": "nosuch", "args": ["a"]}`, code: http.StatusBadRequest},
+		{req: `{"op": "sub", "args": ["a"]}`, code: http.StatusBadRequest},
+		{req: `{"op": "add", "args": ["a", "[[a]]"]}`, code: http.StatusBadRequest},
+		{req: `{"op": "mul", "args": ["[[a, b]]", "[[a, b]]"]}`, code: http.StatusBadRequest},
+		{req: `{"op": "rx", "args": ["2*t"]}`, code: http.StatusBadRequest},
+		{req: `not json`, code: http.StatusBadRequest},
+		{req: `{"op": "parse", "args": ["a+b+c+d+e"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "mul", "args": ["a+b+c", "a-b"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "substitute", "args": ["x^4", "x", "a+b"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "parse", "args": ["x+٣"]}`, code: http.StatusBadRequest},
+		{req: `{"op": "identity", "args": ["5"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "substitute", "args": ["x", "x", "x+1"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "parse", "args": ["` + strings.Repeat("(", 400000) + `"]}`, code: http.StatusBadRequest},
+		{req: `{"op": "identity", "args": ["100000000000"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "identity", "args": ["-1"]}`, code: http.StatusBadRequest},
+	}
+	for i, v := range vs {
+		code, resp := post(t, s, v.req)
+		if code != v.code || resp.Error == "" {
+			t.Errorf("[%d] %s: got status=%d error=%q, want status=%d", i, v.req, code, resp.Error, v.code)
+		}
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/eval", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET got status=%d", w.Code)
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	// Without a term limit, the dimension of a matrix is still bounded.
+	code, resp := post(t, &Server{}, `{"op": "identity", "args": ["50000"]}`)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("got status=%d error=%q, want limit exceeded", code, resp.Error)
+	}
+	// An identity matrix has only as many terms as its dimension.
+	code, resp = post(t, &Server{MaxTerms: 10000}, `{"op": "identity", "args": ["200"]}`)
+	if code != http.StatusOK || resp.Terms != 200 {
+		t.Errorf("got status=%d terms=%d error=%q, want 200 terms", code, resp.Terms, resp.Error)
+	}
+}
+
+func TestUnknownOp(t *testing.T) {
+	// The operation is rejected before its costly argument is parsed.
+	start := time.Now()
+	code, resp := post(t, &Server{}, `{"op": "nosuch", "args": ["(a+b+c+d+e+f)^40"]}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("got status=%d error=%q, want bad request", code, resp.Error)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("took %v to reject", d)
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := (&Server{}).eval(ctx, Request{Op: "mul", Args: []string{"a+b", "a-b"}})
+	if h, ok := err.(*httpError); !ok || h.status != http.StatusServiceUnavailable {
+		t.Errorf("got err=%v, want unavailable", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s := &Server{Timeout: time.Nanosecond}
+	code, resp := post(t, s, `{"op": "mul", "args": ["(a+b+c+d+e+f)^6", "(a+b+c+d+e+f)^6"]}`)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("got status=%d error=%q, want timeout", code, resp.Error)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	s := &Server{MaxDegree: 4, MaxCoeffBits: 8}
+	vs := []struct {
+		req  string
+		code int
+	}{
+		{req: `{"op": "mul", "args": ["x^2", "x^2"]}`, code: http.StatusOK},
+		{req: `{"op": "mul", "args": ["x^2", "x^3"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "mul", "args": ["[[x^3]]", "[[x^2]]"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "substitute", "args": ["y^2", "y", "x^3"]}`, code: http.StatusUnprocessableEntity},
+		{req: `{"op": "mul", "args": ["100*x", "100*x"]}`, code: http.StatusUnprocessableEntity},
+	}
+	for i, v := range vs {
+		code, resp := post(t, s, v.req)
+		if code != v.code {
+			t.Errorf("[%d] %s: got status=%d error=%q, want status=%d", i, v.req, code, resp.Error, v.code)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	vs := []struct {
+		s    *Server
+		req  string
+		code int
+	}{
+		{s: &Server{MaxTerms: 1000, Timeout: 5 * time.Second}, req: `{"op": "parse", "args": ["(a+b+c+d)^80"]}`, code: http.StatusUnprocessableEntity},
+		{s: &Server{MaxTerms: 1000}, req: `{"op": "add", "args": ["[[(a+b+c+d)^80]]"]}`, code: http.StatusUnprocessableEntity},
+		{s: &Server{MaxDegree: 10}, req: `{"op": "parse", "args": ["x^11"]}`, code: http.StatusUnprocessableEntity},
+		{s: &Server{Timeout: 50 * time.Millisecond}, req: `{"op": "parse", "args": ["(a+b+c+d)^80"]}`, code: http.StatusServiceUnavailable},
+		{s: &Server{MaxTerms: 10000, Timeout: 500 * time.Millisecond}, req: `{"op": "parse", "args": ["(a+b+c+d)^80"]}`, code: http.StatusServiceUnavailable},
+	}
+	for i, v := range vs {
+		start := time.Now()
+		code, resp := post(t, v.s, v.req)
+		if code != v.code {
+			t.Errorf("[%d] %s: got status=%d error=%q, want status=%d", i, v.req, code, resp.Error, v.code)
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Errorf("[%d] %s: took %v", i, v.req, d)
+		}
+	}
+}
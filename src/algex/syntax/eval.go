@@ -0,0 +1,180 @@
+package syntax
+
+import (
+	"context"
+
+	"algex/matrix"
+	"algex/terms"
+)
+
+// Value is the value of an expression evaluated by EvalCtx. Exactly one
+// of Exp and Matrix is set.
+type Value struct {
+	Exp    *terms.Exp
+	Matrix *matrix.Matrix
+	// Note annotates an expression for the Scope that computed it. It
+	// is carried from the left operand of sums, products and powers.
+	Note string
+}
+
+// Scope supplies the variables and functions that extend a dialect
+// for EvalCtx, so that an interpreter can share the grammar of the
+// dialect.
+type Scope struct {
+	// Var returns the value of the variable name, if it is defined.
+	Var func(name string) (Value, bool)
+	// Funcs holds functions that parse their own arguments with an
+	// Args. They take precedence over the functions of the dialect.
+	Funcs map[string]func(a *Args) (Value, error)
+}
+
+// fn returns the function name of sc, if sc has one.
+func (sc *Scope) fn(name string) (func(a *Args) (Value, error), bool) {
+	if sc == nil {
+		return nil, false
+	}
+	f, ok := sc.Funcs[name]
+	return f, ok
+}
+
+// lookup returns the value of the variable name of sc, if it is
+// defined.
+func (sc *Scope) lookup(name string) (value, bool) {
+	if sc == nil || sc.Var == nil {
+		return value{}, false
+	}
+	v, ok := sc.Var(name)
+	return value{e: v.Exp, m: v.Matrix, note: v.Note}, ok
+}
+
+// export converts v, in which a list is taken to be a matrix, into a
+// Value.
+func (v value) export() (Value, error) {
+	v, err := v.operand()
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Exp: v.e, Matrix: v.m, Note: v.note}, nil
+}
+
+// EvalCtx evaluates s, written in the syntax of d, with the variables
+// and functions of sc. Lists are evaluated as matrices, and matrices
+// may be added, multiplied and raised to non-negative powers.
+// Evaluation fails if ctx is done or the terms.Limits of ctx are
+// exceeded.
+func (d *Dialect) EvalCtx(ctx context.Context, s string, sc *Scope) (Value, error) {
+	v, err := d.parse(ctx, s, sc)
+	if err != nil {
+		return Value{}, err
+	}
+	return v.export()
+}
+
+// Args parses the arguments of a call to a function of a Scope. Each
+// argument is parsed in turn by one of the methods of Args.
+type Args struct {
+	p *parser
+	n int
+	// err is the last error returned by parsing, which is already
+	// located.
+	err error
+}
+
+// call calls the Scope function f named by t, and parses the
+// delimiter that ends its arguments.
+func (p *parser) call(t token, f func(a *Args) (Value, error)) (value, error) {
+	p.next()
+	a := &Args{p: p}
+	v, err := f(a)
+	if err != nil {
+		if err == a.err {
+			return value{}, err
+		}
+		return value{}, p.errorf(t, "%w", err)
+	}
+	if err := p.expect(p.d.end); err != nil {
+		return value{}, err
+	}
+	return value{e: v.Exp, m: v.Matrix, note: v.Note}, nil
+}
+
+// Context returns the context of the evaluation.
+func (a *Args) Context() context.Context {
+	return a.p.ctx
+}
+
+// More indicates that another argument follows.
+func (a *Args) More() bool {
+	return a.p.is(",")
+}
+
+// start parses the comma that precedes all but the first argument.
+func (a *Args) start() error {
+	if a.n++; a.n > 1 {
+		return a.fail(a.p.expect(","))
+	}
+	return nil
+}
+
+// fail records a parsing error.
+func (a *Args) fail(err error) error {
+	if err != nil {
+		a.err = err
+	}
+	return err
+}
+
+// Value parses and evaluates the next argument.
+func (a *Args) Value() (Value, error) {
+	if err := a.start(); err != nil {
+		return Value{}, err
+	}
+	v, err := a.p.sum()
+	if err != nil {
+		return Value{}, a.fail(err)
+	}
+	return v.export()
+}
+
+// Literal parses and evaluates the next argument like Value, but does
+// not look up variables, so that names stand for symbols.
+func (a *Args) Literal() (Value, error) {
+	defer func(l bool) { a.p.literal = l }(a.p.literal)
+	a.p.literal = true
+	return a.Value()
+}
+
+// Symbol parses the next argument as a symbol name.
+func (a *Args) Symbol() (string, error) {
+	if err := a.start(); err != nil {
+		return "", err
+	}
+	return a.symbol()
+}
+
+// symbol parses a symbol name.
+func (a *Args) symbol() (string, error) {
+	t := a.p.next()
+	if t.kind != tIdent {
+		return "", a.fail(a.p.errorf(t, "symbol required"))
+	}
+	return t.text, nil
+}
+
+// Word parses the next argument as a symbol name, optionally prefixed
+// by a number written against it, such as t or 2t.
+func (a *Args) Word() (string, error) {
+	if err := a.start(); err != nil {
+		return "", err
+	}
+	t := a.p.peek()
+	if t.kind != tNum {
+		return a.symbol()
+	}
+	a.p.next()
+	if u := a.p.peek(); u.kind == tIdent && u.pos == t.pos+len(t.text) {
+		a.p.next()
+		return t.text + u.text, nil
+	}
+	return "", a.fail(a.p.errorf(t, "symbol required"))
+}
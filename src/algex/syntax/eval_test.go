@@ -0,0 +1,87 @@
+package syntax
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestEvalCtx(t *testing.T) {
+	x, _ := Algex.Parse("a+b")
+	sc := &Scope{
+		Var: func(name string) (Value, bool) {
+			if name == "x" {
+				return Value{Exp: x, Note: "a"}, true
+			}
+			return Value{}, false
+		},
+		Funcs: map[string]func(a *Args) (Value, error){
+			"twice": func(a *Args) (Value, error) {
+				v, err := a.Value()
+				if err != nil {
+					return v, err
+				}
+				return Value{Exp: terms.Mul(v.Exp, terms.NewExp([]factor.Value{factor.D(2, 1)}))}, nil
+			},
+			"name": func(a *Args) (Value, error) {
+				w, err := a.Word()
+				if err != nil {
+					return Value{}, err
+				}
+				v, err := a.Literal()
+				if err != nil {
+					return v, err
+				}
+				return Value{Exp: terms.Mul(v.Exp, terms.NewExp([]factor.Value{factor.S(w)}))}, nil
+			},
+		},
+	}
+	vs := []struct {
+		s, want, note string
+	}{
+		{s: "x^2", want: "2*a*b+a^2+b^2", note: "a"},
+		{s: "-x", want: "-a-b", note: "a"},
+		{s: "twice(x) - x", want: "a+b"},
+		{s: "name(2t, x)", want: "2t*x"},
+		{s: "[[1, x], [0, 1]]^3", want: "[[1, 3*a+3*b], [0, 1]]"},
+		{s: "2*[[x]] - [[a]]", want: "[[a+2*b]]"},
+		{s: "[x, 1]", want: "[[a+b], [1]]"},
+	}
+	for i, v := range vs {
+		got, err := Algex.EvalCtx(context.Background(), v.s, sc)
+		if err != nil {
+			t.Errorf("[%d] %q failed: %v", i, v.s, err)
+			continue
+		}
+		s := got.Exp.String()
+		if got.Matrix != nil {
+			s = got.Matrix.String()
+		}
+		if s != v.want || got.Note != v.note {
+			t.Errorf("[%d] %q got=%q (%q) want=%q (%q)", i, v.s, s, got.Note, v.want, v.note)
+		}
+	}
+	for i, s := range []string{
+		"twice(x, x)",
+		"name(x + 1, a)",
+		"name(2 t, a)",
+		"[[1, 2]] + 1",
+		"[[1, 2]]^2",
+		"[[1]]^-1",
+		"a/[[1]]",
+		"nosuch(a)",
+		strings.Repeat("twice(", 2000),
+	} {
+		if v, err := Algex.EvalCtx(context.Background(), s, sc); err == nil {
+			t.Errorf("[%d] %q got=%v, want error", i, s, v)
+		}
+	}
+	ctx := terms.WithLimits(context.Background(), terms.Limits{MaxTerms: 10})
+	if _, err := Algex.EvalCtx(ctx, "[[x]]*[[x+c]]*[[x+c+d]]", sc); !errors.Is(err, terms.ErrLimit) {
+		t.Errorf("got err=%v, want %v", err, terms.ErrLimit)
+	}
+}
@@ -0,0 +1,87 @@
+package syntax
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"algex/matrix"
+	"algex/terms"
+)
+
+// latexEscapes maps characters special to LaTeX to their escaped form.
+var latexEscapes = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`_`, `\_`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`^`, `\^{}`,
+	`~`, `\~{}`,
+)
+
+// latexSymbol returns a symbol name in LaTeX form. Names longer than a
+// single character are set as a single italic word.
+func latexSymbol(sym string) string {
+	if utf8.RuneCountInString(sym) == 1 {
+		return latexEscapes.Replace(sym)
+	}
+	return `\mathit{` + latexEscapes.Replace(sym) + `}`
+}
+
+// LaTeX returns an expression as LaTeX math mode source.
+func LaTeX(e *terms.Exp) string {
+	ts := e.Terms()
+	if len(ts) == 0 {
+		return "0"
+	}
+	var b strings.Builder
+	for i, t := range ts {
+		n := &big.Rat{}
+		n.Abs(t[0].Num())
+		var xs []string
+		if len(t) == 1 || n.Cmp(big.NewRat(1, 1)) != 0 {
+			if n.IsInt() {
+				xs = append(xs, n.Num().String())
+			} else {
+				xs = append(xs, fmt.Sprintf(`\frac{%v}{%v}`, n.Num(), n.Denom()))
+			}
+		}
+		for _, f := range t[1:] {
+			s := latexSymbol(f.Sym())
+			if f.Pow() != 1 {
+				s = fmt.Sprintf("%s^{%d}", s, f.Pow())
+			}
+			xs = append(xs, s)
+		}
+		switch {
+		case t[0].Num().Sign() < 0 && i == 0:
+			b.WriteString("-")
+		case t[0].Num().Sign() < 0:
+			b.WriteString(" - ")
+		case i != 0:
+			b.WriteString(" + ")
+		}
+		b.WriteString(strings.Join(xs, " "))
+	}
+	return b.String()
+}
+
+// LaTeXMatrix returns a matrix as LaTeX math mode source.
+func LaTeXMatrix(m *matrix.Matrix) string {
+	rows, cols := m.Dims()
+	var rs []string
+	for r := 0; r < rows; r++ {
+		var cs []string
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			cs = append(cs, LaTeX(e))
+		}
+		rs = append(rs, strings.Join(cs, " & "))
+	}
+	return `\begin{pmatrix} ` + strings.Join(rs, ` \\ `) + ` \end{pmatrix}`
+}
@@ -0,0 +1,32 @@
+package syntax
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/rotation"
+	"algex/terms"
+)
+
+func TestLaTeX(t *testing.T) {
+	vs := []struct {
+		e *terms.Exp
+		s string
+	}{
+		{e: terms.NewExp(), s: "0"},
+		{e: terms.NewExp([]factor.Value{factor.D(-1, 1)}), s: "-1"},
+		{
+			e: terms.NewExp([]factor.Value{factor.D(-1, 3), factor.S("x"), factor.Sp("y", 2)}, []factor.Value{factor.Sp("c2t", -1)}),
+			s: `\mathit{c2t}^{-1} - \frac{1}{3} x y^{2}`,
+		},
+		{e: terms.NewExp([]factor.Value{factor.S("s_a")}), s: `\mathit{s\_a}`},
+	}
+	for i, v := range vs {
+		if got := LaTeX(v.e); got != v.s {
+			t.Errorf("[%d] got=%q want=%q", i, got, v.s)
+		}
+	}
+	if got, want := LaTeXMatrix(rotation.RZ("t")), `\begin{pmatrix} \mathit{ct} & -\mathit{st} & 0 \\ \mathit{st} & \mathit{ct} & 0 \\ 0 & 0 & 1 \end{pmatrix}`; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+}
@@ -0,0 +1,707 @@
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"algex/factor"
+	"algex/matrix"
+	"algex/terms"
+)
+
+// value is the result of parsing part of the input. Exactly one of e,
+// list and m is set.
+type value struct {
+	e    *terms.Exp
+	list []value
+	m    *matrix.Matrix
+	// note is the Note of a Value.
+	note string
+}
+
+// num converts a rational number to a value.
+func num(n *big.Rat) value {
+	return value{e: terms.NewExp([]factor.Value{factor.R(n)})}
+}
+
+// scalar returns the expression held by v.
+func (v value) scalar() (*terms.Exp, error) {
+	if v.e == nil {
+		return nil, fmt.Errorf("expression required")
+	}
+	return v.e, nil
+}
+
+// integer returns the integer held by v.
+func (v value) integer() (*big.Int, error) {
+	if v.e != nil {
+		if n, ok := v.e.AsNumber(); ok && n.IsInt() {
+			return n.Num(), nil
+		}
+	}
+	return nil, fmt.Errorf("integer required")
+}
+
+// toMatrix converts a list of rows, or a list of elements which is
+// treated as a column vector, into a matrix.
+func toMatrix(rows []value) (value, error) {
+	if len(rows) == 0 {
+		return value{}, fmt.Errorf("empty matrix")
+	}
+	var data [][]*terms.Exp
+	for i, r := range rows {
+		if r.e != nil {
+			data = append(data, []*terms.Exp{r.e})
+			continue
+		}
+		if r.list == nil {
+			return value{}, fmt.Errorf("matrix row %d is not a list", i)
+		}
+		var row []*terms.Exp
+		for _, x := range r.list {
+			e, err := x.scalar()
+			if err != nil {
+				return value{}, fmt.Errorf("matrix row %d: %v", i, err)
+			}
+			row = append(row, e)
+		}
+		data = append(data, row)
+	}
+	for i, row := range data {
+		if len(row) != len(data[0]) {
+			return value{}, fmt.Errorf("matrix row %d has %d columns, not %d", i, len(row), len(data[0]))
+		}
+	}
+	m, err := matrix.NewMatrix(len(data), len(data[0]))
+	if err != nil {
+		return value{}, err
+	}
+	for r, row := range data {
+		for c, e := range row {
+			m.Set(r, c, e)
+		}
+	}
+	return value{m: m}, nil
+}
+
+// rationalFunc implements Rational(p, q).
+func rationalFunc(_ context.Context, args []value) (value, error) {
+	if len(args) != 2 {
+		return value{}, fmt.Errorf("Rational needs 2 arguments, not %d", len(args))
+	}
+	p, err := args[0].integer()
+	if err != nil {
+		return value{}, err
+	}
+	q, err := args[1].integer()
+	if err != nil {
+		return value{}, err
+	}
+	if q.Sign() == 0 {
+		return value{}, fmt.Errorf("division by zero")
+	}
+	return num(new(big.Rat).SetFrac(p, q)), nil
+}
+
+// integerFunc implements Integer(n).
+func integerFunc(_ context.Context, args []value) (value, error) {
+	if len(args) != 1 {
+		return value{}, fmt.Errorf("Integer needs 1 argument, not %d", len(args))
+	}
+	n, err := args[0].integer()
+	if err != nil {
+		return value{}, err
+	}
+	return num(new(big.Rat).SetInt(n)), nil
+}
+
+// matrixFunc implements Matrix(list).
+func matrixFunc(_ context.Context, args []value) (value, error) {
+	if len(args) != 1 || args[0].list == nil {
+		return value{}, fmt.Errorf("Matrix needs a single list argument")
+	}
+	return toMatrix(args[0].list)
+}
+
+// rowsFunc implements matrix(row, ...).
+func rowsFunc(_ context.Context, args []value) (value, error) {
+	for i, a := range args {
+		if a.list == nil {
+			return value{}, fmt.Errorf("matrix row %d is not a list", i)
+		}
+	}
+	return toMatrix(args)
+}
+
+// powerFunc implements Power[x, n].
+func powerFunc(ctx context.Context, args []value) (value, error) {
+	if len(args) != 2 {
+		return value{}, fmt.Errorf("Power needs 2 arguments, not %d", len(args))
+	}
+	return power(ctx, args[0], args[1])
+}
+
+// timesFunc implements Times[x, ...].
+func timesFunc(ctx context.Context, args []value) (value, error) {
+	es, err := scalars(args)
+	if err != nil {
+		return value{}, err
+	}
+	if len(es) == 0 {
+		return num(big.NewRat(1, 1)), nil
+	}
+	e, err := terms.MulCtx(ctx, es...)
+	return value{e: e}, err
+}
+
+// plusFunc implements Plus[x, ...].
+func plusFunc(_ context.Context, args []value) (value, error) {
+	es, err := scalars(args)
+	if err != nil {
+		return value{}, err
+	}
+	return value{e: terms.Add(es...)}, nil
+}
+
+// scalars converts a list of values into expressions.
+func scalars(args []value) ([]*terms.Exp, error) {
+	var es []*terms.Exp
+	for _, a := range args {
+		e, err := a.scalar()
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, e)
+	}
+	return es, nil
+}
+
+// operand converts a list used as an operand of arithmetic into a
+// matrix. Other values are returned unchanged.
+func (v value) operand() (value, error) {
+	if v.list != nil {
+		return toMatrix(v.list)
+	}
+	return v, nil
+}
+
+// one and minusOne are the expressions 1 and -1.
+var (
+	one      = terms.NewExp([]factor.Value{factor.D(1, 1)})
+	minusOne = terms.NewExp([]factor.Value{factor.D(-1, 1)})
+)
+
+// add returns a+b, or a-b when op is "-". Both must be expressions or
+// both matrices.
+func add(a, b value, op string) (value, error) {
+	a, err := a.operand()
+	if err != nil {
+		return value{}, err
+	}
+	if b, err = b.operand(); err != nil {
+		return value{}, err
+	}
+	switch {
+	case a.e != nil && b.e != nil:
+		if op == "-" {
+			return value{e: terms.Sub(a.e, b.e), note: a.note}, nil
+		}
+		return value{e: terms.Add(a.e, b.e), note: a.note}, nil
+	case a.m != nil && b.m != nil:
+		s := one
+		if op == "-" {
+			s = minusOne
+		}
+		m, err := a.m.Sum(b.m, s)
+		return value{m: m}, err
+	}
+	return value{}, fmt.Errorf("unable to add a matrix and an expression")
+}
+
+// mul returns a*b under ctx. A matrix may be multiplied by a matrix
+// or an expression.
+func mul(ctx context.Context, a, b value) (value, error) {
+	a, err := a.operand()
+	if err != nil {
+		return value{}, err
+	}
+	if b, err = b.operand(); err != nil {
+		return value{}, err
+	}
+	switch {
+	case a.e != nil && b.e != nil:
+		e, err := terms.MulCtx(ctx, a.e, b.e)
+		return value{e: e, note: a.note}, err
+	case a.m != nil && b.m != nil:
+		m, err := a.m.MulCtx(ctx, b.m)
+		return value{m: m}, err
+	case a.m != nil:
+		return scale(ctx, a.m, b.e)
+	}
+	return scale(ctx, b.m, a.e)
+}
+
+// scale multiplies each element of m by s under ctx, treating nil
+// elements as zero.
+func scale(ctx context.Context, m *matrix.Matrix, s *terms.Exp) (value, error) {
+	rows, cols := m.Dims()
+	n, err := matrix.NewMatrix(rows, cols)
+	if err != nil {
+		return value{}, err
+	}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			if e == nil {
+				e = terms.NewExp()
+			}
+			if e, err = terms.MulCtx(ctx, e, s); err != nil {
+				return value{}, err
+			}
+			n.Set(r, c, e)
+		}
+	}
+	return value{m: n}, nil
+}
+
+// power raises x to the integer power n under ctx. A matrix must be
+// square and n non-negative.
+func power(ctx context.Context, x, n value) (value, error) {
+	x, err := x.operand()
+	if err != nil {
+		return value{}, err
+	}
+	p, err := n.integer()
+	if err != nil {
+		return value{}, fmt.Errorf("exponent: %v", err)
+	}
+	if !p.IsInt64() || p.Int64() != int64(int(p.Int64())) {
+		return value{}, fmt.Errorf("exponent %v too large", p)
+	}
+	k := int(p.Int64())
+	if x.e != nil {
+		e, err := terms.PowCtx(ctx, x.e, k)
+		return value{e: e, note: x.note}, err
+	}
+	rows, cols := x.m.Dims()
+	if rows != cols {
+		return value{}, fmt.Errorf("unable to raise a %dx%d matrix to a power", rows, cols)
+	}
+	if k < 0 {
+		return value{}, fmt.Errorf("unable to raise a matrix to a negative power")
+	}
+	r, err := matrix.Identity(rows)
+	for m := x.m; err == nil && k > 0; k >>= 1 {
+		if k&1 == 1 {
+			r, err = r.MulCtx(ctx, m)
+		}
+		if err == nil && k > 1 {
+			m, err = m.MulCtx(ctx, m)
+		}
+	}
+	return value{m: r}, err
+}
+
+// token kinds.
+const (
+	tEOF = iota
+	tNum
+	tIdent
+	tOp
+)
+
+// token is a lexical element of the input.
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+// ScanNumber returns the length of the run of the ASCII digits 0-9 that
+// begins s. Other Unicode digits are not numbers.
+func ScanNumber(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// Token is a lexical element of an expression.
+type Token struct {
+	// Text is the text of the token and Pos its byte offset in the
+	// input.
+	Text string
+	Pos  int
+	// Num and Ident indicate that the token is a number or a symbol
+	// name. Otherwise it is an operator.
+	Num, Ident bool
+}
+
+// Lex splits s into the tokens of d, so other parsers can share its
+// lexical rules.
+func (d *Dialect) Lex(s string) ([]Token, error) {
+	ts, err := d.lex(s)
+	if err != nil {
+		return nil, err
+	}
+	var xs []Token
+	for _, t := range ts[:len(ts)-1] {
+		xs = append(xs, Token{Text: t.text, Pos: t.pos, Num: t.kind == tNum, Ident: t.kind == tIdent})
+	}
+	return xs, nil
+}
+
+// lex splits the input into tokens.
+func (d *Dialect) lex(s string) ([]token, error) {
+	var ts []token
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += n
+			continue
+		case r >= '0' && r <= '9':
+			j := i + ScanNumber(s[i:])
+			if j < len(s) && s[j] == '.' {
+				return nil, fmt.Errorf("at %d: only exact numbers are supported", i)
+			}
+			ts = append(ts, token{kind: tNum, text: s[i:j], pos: i})
+			i = j
+			continue
+		case d.identRune(r, true):
+			j := i + n
+			for j < len(s) {
+				r, n := utf8.DecodeRuneInString(s[j:])
+				if !d.identRune(r, false) {
+					break
+				}
+				j += n
+			}
+			ts = append(ts, token{kind: tIdent, text: s[i:j], pos: i})
+			i = j
+			continue
+		}
+		op := ""
+		if strings.HasPrefix(s[i:], d.pow) {
+			op = d.pow
+		} else if strings.ContainsRune("+-*/()[]{},", r) {
+			op = string(r)
+		} else {
+			return nil, fmt.Errorf("at %d: unexpected %q", i, r)
+		}
+		ts = append(ts, token{kind: tOp, text: op, pos: i})
+		i += len(op)
+	}
+	return append(ts, token{kind: tEOF, pos: len(s)}), nil
+}
+
+// parser holds the state of parsing a token list.
+type parser struct {
+	d   *Dialect
+	ts  []token
+	i   int
+	ctx context.Context
+	// scope, when set, supplies variables and functions.
+	scope *Scope
+	// literal disables the lookup of variables, so that names are
+	// symbols.
+	literal bool
+	// depth counts the operands being parsed, which bounds the
+	// recursion on deeply nested input.
+	depth int
+}
+
+// maxDepth is the deepest nesting of operands the parser accepts.
+const maxDepth = 1000
+
+// peek returns the next token.
+func (p *parser) peek() token {
+	return p.ts[p.i]
+}
+
+// next consumes and returns the next token.
+func (p *parser) next() token {
+	t := p.ts[p.i]
+	if t.kind != tEOF {
+		p.i++
+	}
+	return t
+}
+
+// is indicates that the next token is the operator op.
+func (p *parser) is(op string) bool {
+	t := p.peek()
+	return t.kind == tOp && t.text == op
+}
+
+// expect consumes the operator op.
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tOp || t.text != op {
+		return p.errorf(t, "expected %q", op)
+	}
+	return nil
+}
+
+// errorf returns an error located at t.
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	if t.kind == tEOF {
+		return fmt.Errorf("at end: "+format, args...)
+	}
+	return fmt.Errorf("at %d %q: "+format, append([]interface{}{t.pos, t.text}, args...)...)
+}
+
+// parse parses the whole of s as a single value under ctx, with the
+// variables and functions of sc.
+func (d *Dialect) parse(ctx context.Context, s string, sc *Scope) (value, error) {
+	ts, err := d.lex(s)
+	if err != nil {
+		return value{}, err
+	}
+	p := &parser{d: d, ts: ts, ctx: ctx, scope: sc}
+	v, err := p.sum()
+	if err != nil {
+		return value{}, err
+	}
+	if t := p.peek(); t.kind != tEOF {
+		return value{}, p.errorf(t, "unexpected input")
+	}
+	return v, nil
+}
+
+// Parse parses an expression written in the syntax of d.
+func (d *Dialect) Parse(s string) (*terms.Exp, error) {
+	return d.ParseCtx(context.Background(), s)
+}
+
+// ParseCtx parses an expression, like Parse. It fails if ctx is done or
+// the terms.Limits of ctx are exceeded while evaluating the powers and
+// products written in s.
+func (d *Dialect) ParseCtx(ctx context.Context, s string) (*terms.Exp, error) {
+	v, err := d.parse(ctx, s, nil)
+	if err != nil {
+		return nil, err
+	}
+	if v.e == nil {
+		return nil, fmt.Errorf("%s: not an expression", d.Name)
+	}
+	return v.e, nil
+}
+
+// ParseMatrix parses a matrix written in the syntax of d. A list of
+// expressions is parsed as a column vector.
+func (d *Dialect) ParseMatrix(s string) (*matrix.Matrix, error) {
+	return d.ParseMatrixCtx(context.Background(), s)
+}
+
+// ParseMatrixCtx parses a matrix, like ParseMatrix, under ctx.
+func (d *Dialect) ParseMatrixCtx(ctx context.Context, s string) (*matrix.Matrix, error) {
+	v, err := d.parse(ctx, s, nil)
+	if err != nil {
+		return nil, err
+	}
+	if v.list != nil {
+		if v, err = toMatrix(v.list); err != nil {
+			return nil, err
+		}
+	}
+	if v.m == nil {
+		return nil, fmt.Errorf("%s: not a matrix", d.Name)
+	}
+	return v.m, nil
+}
+
+// sum parses a sequence of terms separated by '+' or '-'.
+func (p *parser) sum() (value, error) {
+	v, err := p.product()
+	if err != nil {
+		return value{}, err
+	}
+	for p.is("+") || p.is("-") {
+		t := p.next()
+		w, err := p.product()
+		if err != nil {
+			return value{}, err
+		}
+		if v, err = add(v, w, t.text); err != nil {
+			return value{}, p.errorf(t, "%v", err)
+		}
+	}
+	return v, nil
+}
+
+// startsOperand indicates that the next token could begin an operand
+// of an implicit multiplication.
+func (p *parser) startsOperand() bool {
+	t := p.peek()
+	return t.kind == tNum || t.kind == tIdent || (t.kind == tOp && t.text == "(")
+}
+
+// product parses a sequence of factors separated by '*' or '/', or
+// simply juxtaposed where the dialect permits it.
+func (p *parser) product() (value, error) {
+	v, err := p.unary()
+	if err != nil {
+		return value{}, err
+	}
+	for {
+		t := p.peek()
+		op := ""
+		if p.is("*") || p.is("/") {
+			op = p.next().text
+		} else if p.d.implicit && p.startsOperand() {
+			op = "*"
+		} else {
+			return v, nil
+		}
+		w, err := p.unary()
+		if err != nil {
+			return value{}, err
+		}
+		if op == "/" {
+			b, err := w.scalar()
+			if err != nil {
+				return value{}, p.errorf(t, "unable to divide by a matrix")
+			}
+			if b, err = terms.Pow(b, -1); err != nil {
+				return value{}, p.errorf(t, "%v", err)
+			}
+			w = value{e: b}
+		}
+		if v, err = mul(p.ctx, v, w); err != nil {
+			return value{}, p.errorf(t, "%w", err)
+		}
+	}
+}
+
+// unary parses an optionally signed power.
+func (p *parser) unary() (value, error) {
+	if p.depth++; p.depth > maxDepth {
+		return value{}, p.errorf(p.peek(), "nested more than %d deep", maxDepth)
+	}
+	defer func() { p.depth-- }()
+	if p.is("-") || p.is("+") {
+		t := p.next()
+		v, err := p.unary()
+		if err != nil || t.text == "+" {
+			return v, err
+		}
+		if v, err = v.operand(); err != nil {
+			return value{}, p.errorf(t, "%v", err)
+		}
+		if v.m != nil {
+			if v, err = scale(p.ctx, v.m, minusOne); err != nil {
+				return value{}, p.errorf(t, "%w", err)
+			}
+			return v, nil
+		}
+		return value{e: terms.Sub(terms.NewExp(), v.e), note: v.note}, nil
+	}
+	return p.power()
+}
+
+// power parses a primary optionally raised to a power. Exponentiation
+// is right associative.
+func (p *parser) power() (value, error) {
+	v, err := p.primary()
+	if err != nil {
+		return value{}, err
+	}
+	if !p.is(p.d.pow) {
+		return v, nil
+	}
+	t := p.next()
+	n, err := p.unary()
+	if err != nil {
+		return value{}, err
+	}
+	if v, err = power(p.ctx, v, n); err != nil {
+		return value{}, p.errorf(t, "%w", err)
+	}
+	return v, nil
+}
+
+// list parses values separated by commas up to the operator end.
+func (p *parser) list(end string) ([]value, error) {
+	vs := []value{}
+	if p.is(end) {
+		p.next()
+		return vs, nil
+	}
+	for {
+		v, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+		if p.is(",") {
+			p.next()
+			continue
+		}
+		if err := p.expect(end); err != nil {
+			return nil, err
+		}
+		return vs, nil
+	}
+}
+
+// primary parses a number, symbol, function call, list or
+// parenthesized expression.
+func (p *parser) primary() (value, error) {
+	t := p.next()
+	switch t.kind {
+	case tNum:
+		n, _ := new(big.Rat).SetString(t.text)
+		return num(n), nil
+	case tIdent:
+		if f, ok := p.scope.fn(t.text); ok && p.is(p.d.call) {
+			return p.call(t, f)
+		}
+		if p.is(p.d.call) {
+			f, ok := p.d.funcs[t.text]
+			if !ok {
+				return value{}, p.errorf(t, "unsupported function")
+			}
+			p.next()
+			args, err := p.list(p.d.end)
+			if err != nil {
+				return value{}, err
+			}
+			v, err := f(p.ctx, args)
+			if err != nil {
+				return value{}, p.errorf(t, "%w", err)
+			}
+			return v, nil
+		}
+		if p.d.reserved[t.text] {
+			return value{}, p.errorf(t, "reserved word")
+		}
+		if v, ok := p.scope.lookup(t.text); ok && !p.literal {
+			return v, nil
+		}
+		return value{e: terms.NewExp([]factor.Value{factor.S(t.text)})}, nil
+	case tOp:
+		switch t.text {
+		case "(":
+			v, err := p.sum()
+			if err != nil {
+				return value{}, err
+			}
+			if err := p.expect(")"); err != nil {
+				return value{}, err
+			}
+			return v, nil
+		case p.d.open:
+			vs, err := p.list(p.d.close)
+			if err != nil {
+				return value{}, err
+			}
+			return value{list: vs}, nil
+		}
+	}
+	return value{}, p.errorf(t, "unexpected input")
+}
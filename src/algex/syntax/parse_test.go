@@ -0,0 +1,165 @@
+package syntax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"algex/factor"
+	"algex/terms"
+)
+
+func TestParse(t *testing.T) {
+	vs := []struct {
+		d    *Dialect
+		s    string
+		want string
+	}{
+		{d: SymPy, s: "(a + b)**2 - Rational(1, 2)*a*b", want: "3/2*a*b+a^2+b^2"},
+		{d: SymPy, s: "x**-2 * 3 / (2*x)", want: "3/2*x^-3"},
+		{d: SymPy, s: "-x**2", want: "-x^2"},
+		{d: SymPy, s: "2**3**2", want: "512"},
+		{d: SymPy, s: "Integer(4)/6 + s_θ", want: "2/3+s_θ"},
+		{d: Maxima, s: "(a - b)^3/a^2", want: "a+3*a^-1*b^2-a^-2*b^3-3*b"},
+		{d: Maxima, s: "1/3*x - x/3", want: "0"},
+		{d: Mathematica, s: "2 x y^2 + Power[x, -1] Rational[1, 2]", want: "2*x*y^2+1/2*x^-1"},
+		{d: Mathematica, s: "Times[a, Plus[a, 1]]", want: "a+a^2"},
+		{d: Mathematica, s: "x (x - 1)", want: "-x+x^2"},
+		{d: Algex, s: "2*a*b+a^2+b^-2", want: "2*a*b+a^2+b^-2"},
+		{d: Algex, s: "-1/3*x*y^2+1/2", want: "1/2-1/3*x*y^2"},
+	}
+	for i, v := range vs {
+		e, err := v.d.Parse(v.s)
+		if err != nil {
+			t.Errorf("[%d] %s: failed to parse %q: %v", i, v.d.Name, v.s, err)
+		} else if got := e.String(); got != v.want {
+			t.Errorf("[%d] %s: %q got=%q want=%q", i, v.d.Name, v.s, got, v.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	vs := []struct {
+		d *Dialect
+		s string
+	}{
+		{d: SymPy, s: "x^2"},
+		{d: SymPy, s: "sin(x)"},
+		{d: SymPy, s: "1.5*x"},
+		{d: SymPy, s: "x/(x + 1)"},
+		{d: SymPy, s: "x**y"},
+		{d: SymPy, s: "(x"},
+		{d: SymPy, s: "x y"},
+		{d: Maxima, s: "x**2"},
+		{d: Mathematica, s: "x_1"},
+		{d: Mathematica, s: "{1, 2} + 1"},
+		{d: SymPy, s: "x + ٣"},
+		{d: Algex, s: "٣"},
+	}
+	for i, v := range vs {
+		if e, err := v.d.Parse(v.s); err == nil {
+			t.Errorf("[%d] %s: parsed %q as %q", i, v.d.Name, v.s, e)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	e := terms.NewExp(
+		[]factor.Value{factor.D(7, 5), factor.S("a"), factor.Sp("b", -3)},
+		[]factor.Value{factor.D(-1, 1), factor.S("c2t")},
+		[]factor.Value{factor.D(1, 9)},
+	)
+	for _, d := range []*Dialect{Algex, SymPy, Maxima, Mathematica} {
+		s, err := d.Format(e)
+		if err != nil {
+			t.Fatalf("%s: failed to format %q: %v", d.Name, e, err)
+		}
+		x, err := d.Parse(s)
+		if err != nil {
+			t.Fatalf("%s: failed to parse %q: %v", d.Name, s, err)
+		}
+		if got, want := x.String(), e.String(); got != want {
+			t.Errorf("%s: %q got=%q want=%q", d.Name, s, got, want)
+		}
+	}
+}
+
+func TestParseMatrix(t *testing.T) {
+	vs := []struct {
+		d    *Dialect
+		s    string
+		want string
+	}{
+		{d: SymPy, s: "Matrix([a, b])", want: "[[a], [b]]"},
+		{d: Maxima, s: "matrix([1, x^2])", want: "[[1, x^2]]"},
+		{d: Mathematica, s: "{{1, 0}, {a b, 1}}", want: "[[1, 0], [a*b, 1]]"},
+		{d: Algex, s: "[[ct, -st], [st, ct]]", want: "[[ct, -st], [st, ct]]"},
+	}
+	for i, v := range vs {
+		m, err := v.d.ParseMatrix(v.s)
+		if err != nil {
+			t.Errorf("[%d] %s: failed to parse %q: %v", i, v.d.Name, v.s, err)
+		} else if got := m.String(); got != v.want {
+			t.Errorf("[%d] %s: %q got=%q want=%q", i, v.d.Name, v.s, got, v.want)
+		}
+	}
+	if _, err := SymPy.ParseMatrix("Matrix([[a, b], [c]])"); err == nil {
+		t.Error("ragged matrix accepted")
+	}
+}
+
+func TestParseCtx(t *testing.T) {
+	ctx := terms.WithLimits(context.Background(), terms.Limits{MaxTerms: 100})
+	for i, s := range []string{"(a+b+c)^60", "(a+b+c)^8*(a+b+c)^8", "Times[(a+b+c)^8, (a+b+c)^8]"} {
+		d := Algex
+		if i == 2 {
+			d = Mathematica
+		}
+		if _, err := d.ParseCtx(ctx, s); !errors.Is(err, terms.ErrLimit) {
+			t.Errorf("[%d] %q got err=%v want %v", i, s, err, terms.ErrLimit)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := Algex.ParseMatrixCtx(ctx, "[[(a+b+c+d)^80]]"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got err=%v want %v", err, context.DeadlineExceeded)
+	}
+	if e, err := Algex.ParseCtx(ctx, "(a+b)^2"); err == nil {
+		t.Errorf("parsed %v after the deadline", e)
+	}
+}
+
+func TestLex(t *testing.T) {
+	ts, err := Algex.Lex("2*x_1^-3")
+	if err != nil {
+		t.Fatalf("failed to lex: %v", err)
+	}
+	if got, want := fmt.Sprint(ts), "[{2 0 true false} {* 1 false false} {x_1 2 false true} {^ 5 false false} {- 6 false false} {3 7 true false}]"; got != want {
+		t.Errorf("got=%s want=%s", got, want)
+	}
+	if n := ScanNumber("12٣4"); n != 2 {
+		t.Errorf("ScanNumber got=%d want=2", n)
+	}
+}
+
+func TestParseDepth(t *testing.T) {
+	vs := []string{
+		strings.Repeat("(", 400000),
+		strings.Repeat("(", 2000) + "x" + strings.Repeat(")", 2000),
+		strings.Repeat("-", 400000) + "x",
+		strings.Repeat("x^", 400000) + "1",
+		strings.Repeat("[", 400000),
+	}
+	for i, s := range vs {
+		if _, err := Algex.Parse(s); err == nil {
+			t.Errorf("[%d] parsed input nested %d deep", i, len(s))
+		}
+	}
+	s := strings.Repeat("(", 500) + "x" + strings.Repeat(")", 500)
+	if e, err := Algex.Parse(s); err != nil || e.String() != "x" {
+		t.Errorf("got=%v, %v want=x", e, err)
+	}
+}
@@ -0,0 +1,269 @@
+// Package syntax prints and parses expressions and matrices in the
+// input syntax of other computer algebra systems.
+//
+// Only the polynomial subset of each syntax is understood: integers,
+// rationals, symbols, integer powers, sums, products, division by a
+// single term and matrix literals. LaTeX output is also supported.
+//
+// EvalCtx extends a dialect with the variables and functions of a
+// Scope, so that an interpreter can share the grammar of the dialect
+// rather than parse expressions itself.
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"algex/factor"
+	"algex/matrix"
+	"algex/terms"
+)
+
+// Dialect describes the syntax of a computer algebra system.
+type Dialect struct {
+	// Name names the dialect.
+	Name string
+
+	// pow is the exponentiation operator.
+	pow string
+	// rational formats a positive non-integer rational.
+	rational func(n *big.Rat) string
+	// matrix formats the rows of a matrix.
+	matrix func(rows []string) string
+	// row formats the elements of a matrix row.
+	row func(els []string) string
+	// underscore indicates that '_' may be used in symbol names.
+	underscore bool
+	// reserved holds words that cannot be used as symbols.
+	reserved map[string]bool
+	// call and end delimit function arguments.
+	call, end string
+	// open and close delimit list literals.
+	open, close string
+	// implicit indicates that juxtaposition means multiplication.
+	implicit bool
+	// funcs holds the functions understood by the parser.
+	funcs map[string]func(ctx context.Context, args []value) (value, error)
+	// declare returns text declaring the listed symbols.
+	declare func(syms []string) string
+}
+
+// words converts a list of words into a set.
+func words(ws ...string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range ws {
+		m[w] = true
+	}
+	return m
+}
+
+// Algex is the syntax used by the String methods of terms.Exp and
+// matrix.Matrix.
+var Algex = &Dialect{
+	Name:       "algex",
+	pow:        "^",
+	rational:   func(n *big.Rat) string { return n.RatString() },
+	matrix:     func(rows []string) string { return "[" + strings.Join(rows, ", ") + "]" },
+	row:        func(els []string) string { return "[" + strings.Join(els, ", ") + "]" },
+	underscore: true,
+	call:       "(",
+	end:        ")",
+	open:       "[",
+	close:      "]",
+	declare:    func([]string) string { return "" },
+}
+
+// SymPy is the syntax of Python using the SymPy package.
+var SymPy = &Dialect{
+	Name:     "sympy",
+	pow:      "**",
+	rational: func(n *big.Rat) string { return fmt.Sprintf("Rational(%v, %v)", n.Num(), n.Denom()) },
+	matrix:   func(rows []string) string { return "Matrix([" + strings.Join(rows, ", ") + "])" },
+	row:      func(els []string) string { return "[" + strings.Join(els, ", ") + "]" },
+	reserved: words("False", "None", "True", "and", "as", "assert", "async",
+		"await", "break", "class", "continue", "def", "del", "elif", "else",
+		"except", "finally", "for", "from", "global", "if", "import", "in",
+		"is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return",
+		"try", "while", "with", "yield", "Matrix", "Rational", "Integer",
+		"symbols"),
+	underscore: true,
+	call:       "(",
+	end:        ")",
+	open:       "[",
+	close:      "]",
+	funcs: map[string]func(context.Context, []value) (value, error){
+		"Rational": rationalFunc,
+		"Integer":  integerFunc,
+		"Matrix":   matrixFunc,
+	},
+	declare: func(syms []string) string {
+		s := "from sympy import Matrix, Rational, symbols\n"
+		if len(syms) != 0 {
+			s += strings.Join(syms, ", ") + " = symbols('" + strings.Join(syms, " ") + "')\n"
+		}
+		return s
+	},
+}
+
+// Maxima is the syntax of the Maxima computer algebra system.
+var Maxima = &Dialect{
+	Name:     "maxima",
+	pow:      "^",
+	rational: func(n *big.Rat) string { return n.RatString() },
+	matrix:   func(rows []string) string { return "matrix(" + strings.Join(rows, ", ") + ")" },
+	row:      func(els []string) string { return "[" + strings.Join(els, ", ") + "]" },
+	reserved: words("and", "or", "not", "if", "then", "else", "elseif",
+		"do", "for", "from", "in", "step", "thru", "unless", "while",
+		"matrix"),
+	underscore: true,
+	call:       "(",
+	end:        ")",
+	open:       "[",
+	close:      "]",
+	funcs: map[string]func(context.Context, []value) (value, error){
+		"matrix": rowsFunc,
+	},
+	declare: func([]string) string { return "" },
+}
+
+// Mathematica is the syntax of the Wolfram Language.
+var Mathematica = &Dialect{
+	Name:     "mathematica",
+	pow:      "^",
+	rational: func(n *big.Rat) string { return n.RatString() },
+	matrix:   func(rows []string) string { return "{" + strings.Join(rows, ", ") + "}" },
+	row:      func(els []string) string { return "{" + strings.Join(els, ", ") + "}" },
+	reserved: words("C", "D", "E", "I", "K", "N", "O", "Pi", "Plus",
+		"Power", "Rational", "Times"),
+	call:     "[",
+	end:      "]",
+	open:     "{",
+	close:    "}",
+	implicit: true,
+	funcs: map[string]func(context.Context, []value) (value, error){
+		"Rational": rationalFunc,
+		"Power":    powerFunc,
+		"Times":    timesFunc,
+		"Plus":     plusFunc,
+	},
+	declare: func([]string) string { return "" },
+}
+
+// validSymbol confirms that sym can be written as a symbol in d.
+func (d *Dialect) validSymbol(sym string) error {
+	for i, r := range sym {
+		if !d.identRune(r, i == 0) {
+			return fmt.Errorf("symbol %q is not valid %s syntax", sym, d.Name)
+		}
+	}
+	if sym == "" || d.reserved[sym] {
+		return fmt.Errorf("symbol %q is reserved in %s", sym, d.Name)
+	}
+	return nil
+}
+
+// identRune indicates that r may appear in a symbol name, with first
+// indicating the first rune of the name.
+func (d *Dialect) identRune(r rune, first bool) bool {
+	switch {
+	case unicode.IsLetter(r):
+		return true
+	case r == '_':
+		return d.underscore
+	case unicode.IsDigit(r):
+		return !first
+	}
+	return false
+}
+
+// Declare returns any text that must precede formatted expressions in
+// order to declare the symbols they use.
+func (d *Dialect) Declare(es ...*terms.Exp) string {
+	seen := make(map[string]bool)
+	var syms []string
+	for _, e := range es {
+		for _, s := range e.Symbols() {
+			if !seen[s] {
+				seen[s] = true
+				syms = append(syms, s)
+			}
+		}
+	}
+	return d.declare(syms)
+}
+
+// Format returns an expression in the syntax of d. It fails if the
+// expression uses a symbol that d cannot represent.
+func (d *Dialect) Format(e *terms.Exp) (string, error) {
+	ts := e.Terms()
+	if len(ts) == 0 {
+		return "0", nil
+	}
+	var b strings.Builder
+	for i, t := range ts {
+		n := &big.Rat{}
+		n.Abs(t[0].Num())
+		var xs []string
+		if len(t) == 1 || n.Cmp(big.NewRat(1, 1)) != 0 {
+			if n.IsInt() {
+				xs = append(xs, n.Num().String())
+			} else {
+				xs = append(xs, d.rational(n))
+			}
+		}
+		for _, f := range t[1:] {
+			s, err := d.factor(f)
+			if err != nil {
+				return "", err
+			}
+			xs = append(xs, s)
+		}
+		switch {
+		case t[0].Num().Sign() < 0 && i == 0:
+			b.WriteString("-")
+		case t[0].Num().Sign() < 0:
+			b.WriteString(" - ")
+		case i != 0:
+			b.WriteString(" + ")
+		}
+		b.WriteString(strings.Join(xs, "*"))
+	}
+	return b.String(), nil
+}
+
+// factor formats a symbolic factor.
+func (d *Dialect) factor(f factor.Value) (string, error) {
+	if err := d.validSymbol(f.Sym()); err != nil {
+		return "", err
+	}
+	switch p := f.Pow(); {
+	case p == 1:
+		return f.Sym(), nil
+	case p < 0:
+		return fmt.Sprintf("%s%s(%d)", f.Sym(), d.pow, p), nil
+	default:
+		return fmt.Sprintf("%s%s%d", f.Sym(), d.pow, p), nil
+	}
+}
+
+// FormatMatrix returns a matrix in the syntax of d.
+func (d *Dialect) FormatMatrix(m *matrix.Matrix) (string, error) {
+	rows, cols := m.Dims()
+	var rs []string
+	for r := 0; r < rows; r++ {
+		var cs []string
+		for c := 0; c < cols; c++ {
+			e, _ := m.El(r, c)
+			s, err := d.Format(e)
+			if err != nil {
+				return "", err
+			}
+			cs = append(cs, s)
+		}
+		rs = append(rs, d.row(cs))
+	}
+	return d.matrix(rs), nil
+}
@@ -0,0 +1,88 @@
+package syntax
+
+import (
+	"testing"
+
+	"algex/factor"
+	"algex/rotation"
+	"algex/terms"
+)
+
+func TestFormat(t *testing.T) {
+	e := terms.NewExp(
+		[]factor.Value{factor.D(-1, 3), factor.S("x"), factor.Sp("y", 2)},
+		[]factor.Value{factor.D(2, 1), factor.Sp("x", -1)},
+		[]factor.Value{factor.D(-5, 1)},
+	)
+	vs := []struct {
+		d *Dialect
+		s string
+	}{
+		{d: SymPy, s: "-5 - Rational(1, 3)*x*y**2 + 2*x**(-1)"},
+		{d: Maxima, s: "-5 - 1/3*x*y^2 + 2*x^(-1)"},
+		{d: Mathematica, s: "-5 - 1/3*x*y^2 + 2*x^(-1)"},
+	}
+	for _, v := range vs {
+		s, err := v.d.Format(e)
+		if err != nil {
+			t.Errorf("%s: failed to format %q: %v", v.d.Name, e, err)
+		} else if s != v.s {
+			t.Errorf("%s: got=%q want=%q", v.d.Name, s, v.s)
+		}
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	vs := []struct {
+		d   *Dialect
+		sym string
+	}{
+		{d: SymPy, sym: "lambda"},
+		{d: Maxima, sym: "do"},
+		{d: Mathematica, sym: "x_1"},
+		{d: Mathematica, sym: "E"},
+	}
+	for _, v := range vs {
+		e := terms.NewExp([]factor.Value{factor.S(v.sym)})
+		if s, err := v.d.Format(e); err == nil {
+			t.Errorf("%s: formatted %q as %q", v.d.Name, v.sym, s)
+		}
+	}
+}
+
+func TestFormatMatrix(t *testing.T) {
+	r := rotation.RZ("t")
+	vs := []struct {
+		d *Dialect
+		s string
+	}{
+		{d: SymPy, s: "Matrix([[ct, -st, 0], [st, ct, 0], [0, 0, 1]])"},
+		{d: Maxima, s: "matrix([ct, -st, 0], [st, ct, 0], [0, 0, 1])"},
+		{d: Mathematica, s: "{{ct, -st, 0}, {st, ct, 0}, {0, 0, 1}}"},
+	}
+	for _, v := range vs {
+		s, err := v.d.FormatMatrix(r)
+		if err != nil {
+			t.Errorf("%s: failed to format %v: %v", v.d.Name, r, err)
+			continue
+		} else if s != v.s {
+			t.Errorf("%s: got=%q want=%q", v.d.Name, s, v.s)
+		}
+		m, err := v.d.ParseMatrix(s)
+		if err != nil {
+			t.Errorf("%s: failed to parse %q: %v", v.d.Name, s, err)
+		} else if got, want := m.String(), r.String(); got != want {
+			t.Errorf("%s: round trip got=%q want=%q", v.d.Name, got, want)
+		}
+	}
+}
+
+func TestDeclare(t *testing.T) {
+	e := terms.NewExp([]factor.Value{factor.S("b"), factor.S("a")})
+	if got, want := SymPy.Declare(e), "from sympy import Matrix, Rational, symbols\na, b = symbols('a b')\n"; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+	if got := Maxima.Declare(e); got != "" {
+		t.Errorf("maxima got=%q", got)
+	}
+}
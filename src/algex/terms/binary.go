@@ -0,0 +1,306 @@
+package terms
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"algex/factor"
+)
+
+// The binary form of a stream of expressions starts with a header of
+// binaryMagic followed by a single binaryVersion byte. Each expression
+// then follows as:
+//
+//	uvarint  number of terms plus one (zero encodes a nil expression)
+//	per term:
+//	  uvarint  numerator length in bytes << 1 | sign bit
+//	  bytes    big-endian numerator magnitude
+//	  uvarint  denominator length in bytes
+//	  bytes    big-endian denominator
+//	  uvarint  number of symbolic factors
+//	  per factor:
+//	    uvarint  symbol table index
+//	    [uvarint length, bytes] symbol name, only when index is new
+//	    varint   power
+//
+// The symbol table is shared by all of the expressions in a stream, so
+// a symbol name is only ever written once.
+var binaryMagic = []byte("algex")
+
+// binaryVersion is the version of the binary encoding written by an
+// Encoder.
+const binaryVersion = 1
+
+// maxBinaryLen bounds the size of any single length prefixed field so
+// corrupt input cannot trigger huge allocations.
+const maxBinaryLen = 1 << 28
+
+// ErrBadEncoding indicates that binary data is not a valid encoding.
+var ErrBadEncoding = errors.New("invalid binary expression encoding")
+
+// Encoder writes a stream of expressions in binary form.
+type Encoder struct {
+	w      io.Writer
+	syms   map[string]uint64
+	header bool
+}
+
+// NewEncoder returns an encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:    w,
+		syms: make(map[string]uint64),
+	}
+}
+
+// Encode writes the binary form of e to the stream. A nil expression
+// is preserved as such.
+func (enc *Encoder) Encode(e *Exp) error {
+	var b []byte
+	if !enc.header {
+		b = append(append(b, binaryMagic...), binaryVersion)
+	}
+	if e == nil {
+		b = binary.AppendUvarint(b, 0)
+	} else {
+		b = binary.AppendUvarint(b, uint64(len(e.terms))+1)
+		var keys []string
+		for k := range e.terms {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b = enc.appendTerm(b, e.terms[k])
+		}
+	}
+	if _, err := enc.w.Write(b); err != nil {
+		return err
+	}
+	enc.header = true
+	return nil
+}
+
+// appendTerm appends the encoding of a single term to b.
+func (enc *Encoder) appendTerm(b []byte, t term) []byte {
+	num := t.coeff.Num()
+	mag := num.Bytes()
+	sign := uint64(0)
+	if num.Sign() < 0 {
+		sign = 1
+	}
+	b = binary.AppendUvarint(b, uint64(len(mag))<<1|sign)
+	b = append(b, mag...)
+	den := t.coeff.Denom().Bytes()
+	b = binary.AppendUvarint(b, uint64(len(den)))
+	b = append(b, den...)
+	b = binary.AppendUvarint(b, uint64(len(t.fact)))
+	for _, f := range t.fact {
+		i, ok := enc.syms[f.Sym()]
+		if !ok {
+			i = uint64(len(enc.syms))
+			enc.syms[f.Sym()] = i
+		}
+		b = binary.AppendUvarint(b, i)
+		if !ok {
+			b = binary.AppendUvarint(b, uint64(len(f.Sym())))
+			b = append(b, f.Sym()...)
+		}
+		b = binary.AppendVarint(b, int64(f.Pow()))
+	}
+	return b
+}
+
+// byteReader is the input needed by a Decoder.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// Decoder reads a stream of expressions written by an Encoder.
+type Decoder struct {
+	r      byteReader
+	syms   []string
+	header bool
+}
+
+// NewDecoder returns a decoder that reads from r. If r does not
+// implement io.ByteReader it is buffered, and the decoder may read
+// beyond the last expression it returns.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{r: br}
+}
+
+// Decode reads the next expression from the stream. At the end of the
+// stream it returns io.EOF.
+func (dec *Decoder) Decode() (*Exp, error) {
+	if !dec.header {
+		h := make([]byte, len(binaryMagic)+1)
+		if _, err := io.ReadFull(dec.r, h); err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("%w: short header", ErrBadEncoding)
+		} else if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(h[:len(binaryMagic)], binaryMagic) {
+			return nil, fmt.Errorf("%w: bad header", ErrBadEncoding)
+		}
+		if v := h[len(binaryMagic)]; v != binaryVersion {
+			return nil, fmt.Errorf("%w: unsupported version %d", ErrBadEncoding, v)
+		}
+		dec.header = true
+	}
+	n, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, dec.fail(err)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	var ts [][]factor.Value
+	for i := uint64(1); i < n; i++ {
+		t, err := dec.readTerm()
+		if err != nil {
+			return nil, dec.fail(err)
+		}
+		ts = append(ts, t)
+	}
+	return NewExp(ts...), nil
+}
+
+// fail converts a read error into a decoding error.
+func (dec *Decoder) fail(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated expression", ErrBadEncoding)
+	}
+	return err
+}
+
+// readTerm reads a single term as a product of factors.
+func (dec *Decoder) readTerm() ([]factor.Value, error) {
+	x, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		return nil, err
+	}
+	mag, err := dec.readBytes(x >> 1)
+	if err != nil {
+		return nil, err
+	}
+	num := new(big.Int).SetBytes(mag)
+	if x&1 == 1 {
+		num.Neg(num)
+	}
+	x, err = binary.ReadUvarint(dec.r)
+	if err != nil {
+		return nil, err
+	}
+	mag, err = dec.readBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	den := new(big.Int).SetBytes(mag)
+	if den.Sign() == 0 {
+		return nil, fmt.Errorf("%w: zero denominator", ErrBadEncoding)
+	}
+	t := []factor.Value{factor.R(new(big.Rat).SetFrac(num, den))}
+	nf, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		return nil, err
+	}
+	for j := uint64(0); j < nf; j++ {
+		i, err := binary.ReadUvarint(dec.r)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case i == uint64(len(dec.syms)):
+			x, err := binary.ReadUvarint(dec.r)
+			if err != nil {
+				return nil, err
+			}
+			s, err := dec.readBytes(x)
+			if err != nil {
+				return nil, err
+			}
+			if len(s) == 0 {
+				return nil, fmt.Errorf("%w: empty symbol", ErrBadEncoding)
+			}
+			dec.syms = append(dec.syms, string(s))
+		case i > uint64(len(dec.syms)):
+			return nil, fmt.Errorf("%w: bad symbol index %d", ErrBadEncoding, i)
+		}
+		p, err := binary.ReadVarint(dec.r)
+		if err != nil {
+			return nil, err
+		}
+		if p == 0 || int64(int(p)) != p {
+			return nil, fmt.Errorf("%w: bad power %d", ErrBadEncoding, p)
+		}
+		t = append(t, factor.Sp(dec.syms[i], int(p)))
+	}
+	return t, nil
+}
+
+// readChunk is the most a field grows by before its bytes are read,
+// so a corrupt length prefix costs no more memory than the input
+// supplies.
+const readChunk = 1 << 16
+
+// readBytes reads a field of n bytes.
+func (dec *Decoder) readBytes(n uint64) ([]byte, error) {
+	if n > maxBinaryLen {
+		return nil, fmt.Errorf("%w: field too long (%d bytes)", ErrBadEncoding, n)
+	}
+	var b []byte
+	for uint64(len(b)) < n {
+		k := n - uint64(len(b))
+		if k > readChunk {
+			k = readChunk
+		}
+		i := len(b)
+		b = append(b, make([]byte, k)...)
+		if _, err := io.ReadFull(dec.r, b[i:]); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// MarshalBinary encodes an expression as a single expression stream.
+func (e *Exp) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an expression encoded by MarshalBinary.
+func (e *Exp) UnmarshalBinary(data []byte) error {
+	dec := NewDecoder(bytes.NewReader(data))
+	x, err := dec.Decode()
+	if err == io.EOF {
+		return fmt.Errorf("%w: no expression", ErrBadEncoding)
+	} else if err != nil {
+		return err
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		return fmt.Errorf("%w: trailing data", ErrBadEncoding)
+	}
+	if x == nil {
+		x = NewExp()
+	}
+	e.terms = x.terms
+	return nil
+}
@@ -0,0 +1,107 @@
+package terms
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+
+	. "algex/factor"
+)
+
+func TestBinary(t *testing.T) {
+	vs := []*Exp{
+		NewExp(),
+		NewExp([]Value{D(-3, 1)}),
+		NewExp([]Value{D(2, 7), S("a"), Sp("b", -3)}, []Value{D(-1, 3), Sp("c2t", 2)}),
+		NewExp([]Value{D(123456789012345, 7), Sp("x", 100000)}, []Value{S("x")}),
+	}
+	for i, v := range vs {
+		b, err := v.MarshalBinary()
+		if err != nil {
+			t.Fatalf("[%d] failed to marshal %q: %v", i, v, err)
+		}
+		e := &Exp{}
+		if err := e.UnmarshalBinary(b); err != nil {
+			t.Fatalf("[%d] failed to unmarshal %q: %v", i, v, err)
+		}
+		if got, want := e.String(), v.String(); got != want {
+			t.Errorf("[%d] got=%q want=%q", i, got, want)
+		}
+	}
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	a := NewExp([]Value{S("a"), S("b")}, []Value{D(1, 2), S("c")})
+	b := NewExp([]Value{Sp("a", 2)}, []Value{D(-1, 1), S("c")})
+	vs := []*Exp{a, nil, b, a}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for i, v := range vs {
+		if err := enc.Encode(v); err != nil {
+			t.Fatalf("[%d] encode failed: %v", i, err)
+		}
+	}
+	// Symbols are only written once per stream.
+	if n := bytes.Count(buf.Bytes(), []byte("c")); n != 1 {
+		t.Errorf("symbol table not shared: %d copies of 'c' in %q", n, buf.Bytes())
+	}
+
+	dec := NewDecoder(&buf)
+	for i, v := range vs {
+		e, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("[%d] decode failed: %v", i, err)
+		}
+		if v == nil {
+			if e != nil {
+				t.Errorf("[%d] got=%q want=nil", i, e)
+			}
+			continue
+		}
+		if got, want := e.String(), v.String(); got != want {
+			t.Errorf("[%d] got=%q want=%q", i, got, want)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("expected end of stream, got %v", err)
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	good, err := NewExp([]Value{D(3, 4), S("x")}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	vs := [][]byte{
+		nil,
+		[]byte("algeb\x01\x01"),
+		[]byte("algex\x07\x01"),
+		good[:len(good)-1],
+		append(append([]byte{}, good...), 1),
+	}
+	for i, v := range vs {
+		e := &Exp{}
+		if err := e.UnmarshalBinary(v); !errors.Is(err, ErrBadEncoding) {
+			t.Errorf("[%d] %q: got err=%v, want ErrBadEncoding", i, v, err)
+		}
+	}
+}
+
+func TestBinaryLongField(t *testing.T) {
+	// A term whose numerator claims 1<<27 bytes, with none supplied.
+	data := binary.AppendUvarint([]byte("algex\x01\x02"), 2<<27)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	e := &Exp{}
+	if err := e.UnmarshalBinary(data); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("got err=%v, want ErrBadEncoding", err)
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes for %d bytes of input", n, len(data))
+	}
+}
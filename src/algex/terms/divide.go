@@ -0,0 +1,139 @@
+package terms
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"algex/factor"
+)
+
+// ErrNotDivisible indicates that an expression is not an exact multiple
+// of another.
+var ErrNotDivisible = errors.New("not exactly divisible")
+
+// IsZero indicates that an expression is zero. A nil expression is
+// zero.
+func (e *Exp) IsZero() bool {
+	return e == nil || len(e.terms) == 0
+}
+
+// powers returns the powers of each symbol of a term.
+func (t term) powers() map[string]int {
+	ps := make(map[string]int)
+	for _, f := range t.fact {
+		ps[f.Sym()] = f.Pow()
+	}
+	return ps
+}
+
+// lead returns the leading term of a non-zero expression under the
+// lexicographic order of its powers of syms.
+func (e *Exp) lead(syms []string) term {
+	var best term
+	var bp map[string]int
+	for _, s := range sortedKeys(e.terms) {
+		t := e.terms[s]
+		tp := t.powers()
+		if bp == nil {
+			best, bp = t, tp
+			continue
+		}
+		for _, sym := range syms {
+			if tp[sym] != bp[sym] {
+				if tp[sym] > bp[sym] {
+					best, bp = t, tp
+				}
+				break
+			}
+		}
+	}
+	return best
+}
+
+// sortedKeys returns the sorted keys of a map of terms.
+func sortedKeys(ts map[string]term) []string {
+	var s []string
+	for x := range ts {
+		s = append(s, x)
+	}
+	sort.Strings(s)
+	return s
+}
+
+// monomial converts a term into a single term expression.
+func (t term) monomial() *Exp {
+	return NewExp(append([]factor.Value{factor.R(t.coeff)}, t.fact...))
+}
+
+// Quo returns the exact quotient a/b of two expressions. Since
+// expressions are Laurent polynomials, division by a single term always
+// succeeds. Otherwise, Quo returns an error wrapping ErrNotDivisible
+// unless b divides a with no remainder.
+func Quo(a, b *Exp) (*Exp, error) {
+	if b.IsZero() {
+		return nil, fmt.Errorf("division of %q by zero", a)
+	}
+	if a.IsZero() {
+		return NewExp(), nil
+	}
+	if len(b.terms) == 1 {
+		d, err := Pow(b, -1)
+		if err != nil {
+			return nil, err
+		}
+		return Mul(a, d), nil
+	}
+
+	// Scale a and b by monomials so the lowest power of each of
+	// their symbols is zero, and then perform polynomial long
+	// division. Since the scaled b has no monomial factor, a/b is a
+	// Laurent polynomial only if the scaled quotient is a polynomial.
+	sa, sb := a.shift(), b.shift()
+	r, d := Mul(a, sa), Mul(b, sb)
+	syms := Add(r, d).Symbols()
+	dl := d.lead(syms)
+	dp := dl.powers()
+	q := NewExp()
+	for !r.IsZero() {
+		rl := r.lead(syms)
+		c := &big.Rat{}
+		t := []factor.Value{factor.R(c.Quo(rl.coeff, dl.coeff))}
+		rp := rl.powers()
+		for s, p := range dp {
+			if rp[s] < p {
+				return nil, fmt.Errorf("%q / %q: %w", a, b, ErrNotDivisible)
+			}
+		}
+		for s, p := range rp {
+			t = append(t, factor.Sp(s, p-dp[s]))
+		}
+		x := NewExp(t)
+		q = Add(q, x)
+		r = Sub(r, Mul(x, d))
+	}
+	ia, err := Pow(sa, -1)
+	if err != nil {
+		return nil, err
+	}
+	return Mul(q, sb, ia), nil
+}
+
+// shift returns the monomial that scales a non-zero expression so the
+// lowest power of each of its symbols is zero.
+func (e *Exp) shift() *Exp {
+	m := []factor.Value{factor.D(1, 1)}
+	for _, s := range e.Symbols() {
+		first, low := true, 0
+		for _, t := range e.terms {
+			if p := t.powers()[s]; first || p < low {
+				first, low = false, p
+			}
+		}
+		if low != 0 {
+			m = append(m, factor.Sp(s, -low))
+		}
+	}
+	return NewExp(m)
+}
@@ -0,0 +1,48 @@
+package terms
+
+import (
+	"errors"
+	"testing"
+
+	. "algex/factor"
+)
+
+func TestQuo(t *testing.T) {
+	a := NewExp([]Value{S("a")}, []Value{S("b")})
+	b := NewExp([]Value{S("a")}, []Value{D(-1, 1), S("b")})
+	c := NewExp([]Value{D(2, 3), Sp("x", -2)}, []Value{S("y"), S("c")})
+	vs := []struct {
+		a, b *Exp
+		s    string
+	}{
+		{a: Mul(a, b), b: a, s: "a-b"},
+		{a: Mul(a, b), b: b, s: "a+b"},
+		{a: Mul(a, b, c), b: Mul(b, c), s: "a+b"},
+		{a: Mul(a, c), b: c, s: "a+b"},
+		{a: NewExp(), b: a, s: "0"},
+		{a: a, b: NewExp([]Value{D(2, 1), Sp("a", 2)}), s: "1/2*a^-1+1/2*a^-2*b"},
+		{a: Mul(a, a, a, b), b: Mul(a, a), s: "a^2-b^2"},
+		// Laurent quotients with negative powers.
+		{a: NewExp([]Value{Sp("x", -1)}, []Value{D(1, 1)}), b: NewExp([]Value{D(1, 1)}, []Value{S("x")}), s: "x^-1"},
+		{a: Mul(a, c), b: Mul(a, NewExp([]Value{Sp("y", 3)})), s: "c*y^-2+2/3*x^-2*y^-3"},
+		{a: Mul(NewExp([]Value{Sp("x", -1)}, []Value{D(1, 1)}), b), b: Mul(b, NewExp([]Value{Sp("x", -2)}, []Value{Sp("x", -1)})), s: "x"},
+		{a: Mul(a, NewExp([]Value{S("x")}, []Value{D(1, 1)})), b: Mul(a, NewExp([]Value{Sp("x", 2)}, []Value{S("x")})), s: "x^-1"},
+	}
+	for i, v := range vs {
+		q, err := Quo(v.a, v.b)
+		if err != nil {
+			t.Errorf("[%d] %q / %q failed: %v", i, v.a, v.b, err)
+		} else if got := q.String(); got != v.s {
+			t.Errorf("[%d] %q / %q got=%q want=%q", i, v.a, v.b, got, v.s)
+		}
+	}
+	if q, err := Quo(Add(Mul(a, b), NewExp([]Value{D(1, 1)})), a); !errors.Is(err, ErrNotDivisible) {
+		t.Errorf("inexact division got=%q err=%v", q, err)
+	}
+	if q, err := Quo(a, NewExp([]Value{Sp("x", -1)}, []Value{S("x")})); !errors.Is(err, ErrNotDivisible) {
+		t.Errorf("inexact Laurent division got=%q err=%v", q, err)
+	}
+	if q, err := Quo(a, NewExp()); err == nil {
+		t.Errorf("division by zero got=%q", q)
+	}
+}
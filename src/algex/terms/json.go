@@ -0,0 +1,61 @@
+package terms
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"algex/factor"
+)
+
+// jsonFactor is the JSON form of a symbolic factor.
+type jsonFactor struct {
+	Sym string `json:"sym"`
+	Pow int    `json:"pow"`
+}
+
+// jsonTerm is the JSON form of a term. The coefficient is a string,
+// like "-2/3", to preserve its precision.
+type jsonTerm struct {
+	Coeff   string       `json:"coeff"`
+	Factors []jsonFactor `json:"factors,omitempty"`
+}
+
+// MarshalJSON encodes an expression as a JSON array of terms, in the
+// order String displays them.
+func (e *Exp) MarshalJSON() ([]byte, error) {
+	js := []jsonTerm{}
+	for _, t := range e.Terms() {
+		j := jsonTerm{Coeff: t[0].Num().RatString()}
+		for _, f := range t[1:] {
+			j.Factors = append(j.Factors, jsonFactor{Sym: f.Sym(), Pow: f.Pow()})
+		}
+		js = append(js, j)
+	}
+	return json.Marshal(js)
+}
+
+// UnmarshalJSON decodes an expression encoded by MarshalJSON.
+func (e *Exp) UnmarshalJSON(data []byte) error {
+	var js []jsonTerm
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+	var ts [][]factor.Value
+	for _, j := range js {
+		n, ok := new(big.Rat).SetString(j.Coeff)
+		if !ok {
+			return fmt.Errorf("invalid coefficient %q", j.Coeff)
+		}
+		t := []factor.Value{factor.R(n)}
+		for _, f := range j.Factors {
+			if f.Sym == "" {
+				return fmt.Errorf("missing symbol name")
+			}
+			t = append(t, factor.Sp(f.Sym, f.Pow))
+		}
+		ts = append(ts, t)
+	}
+	e.terms = NewExp(ts...).terms
+	return nil
+}
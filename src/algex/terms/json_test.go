@@ -0,0 +1,43 @@
+package terms
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "algex/factor"
+)
+
+func TestJSON(t *testing.T) {
+	vs := []struct {
+		e *Exp
+		j string
+	}{
+		{e: NewExp(), j: `[]`},
+		{
+			e: NewExp([]Value{D(-2, 3), S("x"), Sp("y", -2)}, []Value{D(5, 1)}),
+			j: `[{"coeff":"5"},{"coeff":"-2/3","factors":[{"sym":"x","pow":1},{"sym":"y","pow":-2}]}]`,
+		},
+	}
+	for i, v := range vs {
+		b, err := json.Marshal(v.e)
+		if err != nil {
+			t.Fatalf("[%d] failed to marshal %q: %v", i, v.e, err)
+		}
+		if string(b) != v.j {
+			t.Errorf("[%d] got=%s want=%s", i, b, v.j)
+		}
+		e := &Exp{}
+		if err := json.Unmarshal(b, e); err != nil {
+			t.Fatalf("[%d] failed to unmarshal %s: %v", i, b, err)
+		}
+		if got, want := e.String(), v.e.String(); got != want {
+			t.Errorf("[%d] got=%q want=%q", i, got, want)
+		}
+	}
+	for i, j := range []string{`{}`, `[{"coeff":"x"}]`, `[{"coeff":"1","factors":[{"pow":2}]}]`} {
+		e := &Exp{}
+		if err := json.Unmarshal([]byte(j), e); err == nil {
+			t.Errorf("[%d] %s unmarshaled as %q", i, j, e)
+		}
+	}
+}
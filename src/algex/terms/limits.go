@@ -0,0 +1,179 @@
+package terms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"algex/factor"
+)
+
+// Limits bounds the size of the expressions computed by the
+// context-aware operations, such as MulCtx. A zero field imposes no
+// limit. Limits are checked as each term is computed, so intermediate
+// expressions are also bounded.
+type Limits struct {
+	// MaxTerms bounds the number of terms in an expression.
+	MaxTerms int
+	// MaxDegree bounds the magnitude of the power of any symbol.
+	MaxDegree int
+	// MaxCoeffBits bounds the bit length of the numerator and
+	// denominator of any coefficient.
+	MaxCoeffBits int
+}
+
+// ErrLimit indicates that a computation exceeded its Limits.
+var ErrLimit = errors.New("resource limit exceeded")
+
+// LimitError reports which limit a computation exceeded. It wraps
+// ErrLimit.
+type LimitError struct {
+	// Limit is "term", "degree" or "coefficient bits".
+	Limit string
+	Max   int
+}
+
+// Error describes the exceeded limit.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s limit of %d", ErrLimit, e.Limit, e.Max)
+}
+
+// Unwrap allows a LimitError to be detected with errors.Is(err,
+// ErrLimit).
+func (e *LimitError) Unwrap() error {
+	return ErrLimit
+}
+
+// limitsKey is the context key for Limits.
+type limitsKey struct{}
+
+// WithLimits returns a context that applies l to the context-aware
+// operations that use it.
+func WithLimits(ctx context.Context, l Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, l)
+}
+
+// LimitsOf returns the Limits of a context, which are zero if none were
+// set with WithLimits.
+func LimitsOf(ctx context.Context) Limits {
+	l, _ := ctx.Value(limitsKey{}).(Limits)
+	return l
+}
+
+// term confirms that a term of e, with coefficient n and factors fs,
+// is within the limits.
+func (l Limits) term(e *Exp, n *big.Rat, fs []factor.Value) error {
+	if l.MaxTerms > 0 && len(e.terms) > l.MaxTerms {
+		return &LimitError{Limit: "term", Max: l.MaxTerms}
+	}
+	if l.MaxCoeffBits > 0 && (n.Num().BitLen() > l.MaxCoeffBits || n.Denom().BitLen() > l.MaxCoeffBits) {
+		return &LimitError{Limit: "coefficient bits", Max: l.MaxCoeffBits}
+	}
+	if l.MaxDegree > 0 {
+		for _, f := range fs {
+			if p := f.Pow(); p > l.MaxDegree || -p > l.MaxDegree {
+				return &LimitError{Limit: "degree", Max: l.MaxDegree}
+			}
+		}
+	}
+	return nil
+}
+
+// Check confirms that an expression is within the limits.
+func (l Limits) Check(e *Exp) error {
+	if e == nil {
+		return nil
+	}
+	if l.MaxTerms > 0 && len(e.terms) > l.MaxTerms {
+		return &LimitError{Limit: "term", Max: l.MaxTerms}
+	}
+	for _, t := range e.terms {
+		if err := l.term(e, t.coeff, t.fact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkEvery is the number of terms computed between checks for
+// cancellation.
+const checkEvery = 256
+
+// guard enforces the cancellation and limits of a context during a
+// computation. A nil guard enforces nothing.
+type guard struct {
+	ctx   context.Context
+	lim   Limits
+	steps int
+}
+
+// newGuard returns a guard for ctx.
+func newGuard(ctx context.Context) *guard {
+	return &guard{ctx: ctx, lim: LimitsOf(ctx)}
+}
+
+// done returns the error of the context once it is done.
+func (g *guard) done() error {
+	if g == nil {
+		return nil
+	}
+	return g.ctx.Err()
+}
+
+// step is called after a term, with coefficient n and factors fs, has
+// been inserted into e.
+func (g *guard) step(e *Exp, n *big.Rat, fs []factor.Value) error {
+	if g == nil {
+		return nil
+	}
+	if g.steps++; g.steps%checkEvery == 0 {
+		if err := g.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return g.lim.term(e, n, fs)
+}
+
+// power confirms, before any multiplication, that raising e to the
+// power n stays within the degree limit. The power of each symbol in e
+// is multiplied by n in the result.
+func (g *guard) power(e *Exp, n int) error {
+	if g == nil || g.lim.MaxDegree <= 0 {
+		return nil
+	}
+	if n < 0 {
+		n = -n
+	}
+	for _, t := range e.terms {
+		for _, f := range t.fact {
+			p := f.Pow()
+			if p < 0 {
+				p = -p
+			}
+			if p != 0 && n > g.lim.MaxDegree/p {
+				return &LimitError{Limit: "degree", Max: g.lim.MaxDegree}
+			}
+		}
+	}
+	return nil
+}
+
+// MulCtx computes the product of a series of expressions, like Mul. It
+// fails if ctx is done or the Limits of ctx are exceeded.
+func MulCtx(ctx context.Context, as ...*Exp) (*Exp, error) {
+	return mul(newGuard(ctx), as...)
+}
+
+// PowCtx raises an expression to an integer power, like Pow. It fails
+// if ctx is done or the Limits of ctx are exceeded.
+func PowCtx(ctx context.Context, e *Exp, n int) (*Exp, error) {
+	return pow(newGuard(ctx), e, n)
+}
+
+// SubstituteCtx replaces each occurrence of b in an expression with the
+// expression c, like Substitute. It fails if ctx is done or the Limits
+// of ctx are exceeded.
+func SubstituteCtx(ctx context.Context, e *Exp, b []factor.Value, c *Exp) (*Exp, error) {
+	return substitute(newGuard(ctx), e, b, c)
+}
@@ -0,0 +1,104 @@
+package terms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"algex/factor"
+)
+
+func TestLimits(t *testing.T) {
+	ab := NewExp([]factor.Value{factor.S("a")}, []factor.Value{factor.S("b")})
+	big := NewExp([]factor.Value{factor.D(1<<40, 1), factor.S("a")})
+	vs := []struct {
+		lim   Limits
+		f     func(ctx context.Context) (*Exp, error)
+		limit string
+	}{
+		{
+			lim:   Limits{MaxTerms: 4},
+			f:     func(ctx context.Context) (*Exp, error) { return PowCtx(ctx, ab, 4) },
+			limit: "term",
+		},
+		{
+			lim:   Limits{MaxTerms: 5},
+			f:     func(ctx context.Context) (*Exp, error) { return PowCtx(ctx, ab, 4) },
+			limit: "",
+		},
+		{
+			lim:   Limits{MaxDegree: 3},
+			f:     func(ctx context.Context) (*Exp, error) { return MulCtx(ctx, ab, ab, ab, ab) },
+			limit: "degree",
+		},
+		{
+			lim: Limits{MaxDegree: 2},
+			f: func(ctx context.Context) (*Exp, error) {
+				return PowCtx(ctx, NewExp([]factor.Value{factor.S("a")}), -2)
+			},
+			limit: "",
+		},
+		{
+			// The exponent is checked before multiplying.
+			lim: Limits{MaxDegree: 1 << 10},
+			f: func(ctx context.Context) (*Exp, error) {
+				return PowCtx(ctx, ab, 1<<30)
+			},
+			limit: "degree",
+		},
+		{
+			lim:   Limits{MaxCoeffBits: 64},
+			f:     func(ctx context.Context) (*Exp, error) { return MulCtx(ctx, big, big) },
+			limit: "coefficient bits",
+		},
+		{
+			lim: Limits{MaxTerms: 5},
+			f: func(ctx context.Context) (*Exp, error) {
+				x := NewExp([]factor.Value{factor.Sp("x", 5)})
+				return SubstituteCtx(ctx, x, []factor.Value{factor.S("x")}, ab)
+			},
+			limit: "term",
+		},
+	}
+	for i, v := range vs {
+		_, err := v.f(WithLimits(context.Background(), v.lim))
+		if v.limit == "" {
+			if err != nil {
+				t.Errorf("[%d] unexpected failure: %v", i, err)
+			}
+			continue
+		}
+		var le *LimitError
+		if !errors.As(err, &le) || !errors.Is(err, ErrLimit) || le.Limit != v.limit {
+			t.Errorf("[%d] got %v, want %s limit", i, err, v.limit)
+		}
+	}
+}
+
+func TestCtx(t *testing.T) {
+	ab := NewExp([]factor.Value{factor.S("a")}, []factor.Value{factor.S("b")})
+	ctx := context.Background()
+	if got, err := MulCtx(ctx, ab, ab); err != nil || got.String() != Mul(ab, ab).String() {
+		t.Errorf("MulCtx got=%v (%v)", got, err)
+	}
+	x := []factor.Value{factor.S("a")}
+	c := NewExp([]factor.Value{factor.S("c")}, []factor.Value{factor.D(1, 1)})
+	want, _ := Substitute(ab, x, c)
+	if got, err := SubstituteCtx(ctx, ab, x, c); err != nil || got.String() != want.String() {
+		t.Errorf("SubstituteCtx got=%v (%v)", got, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := MulCtx(ctx, ab, ab); !errors.Is(err, context.Canceled) {
+		t.Errorf("MulCtx got %v, want context.Canceled", err)
+	}
+	if _, err := SubstituteCtx(ctx, ab, x, c); !errors.Is(err, context.Canceled) {
+		t.Errorf("SubstituteCtx got %v, want context.Canceled", err)
+	}
+	if LimitsOf(ctx) != (Limits{}) {
+		t.Errorf("got limits %v, want none", LimitsOf(ctx))
+	}
+	if err := (Limits{MaxTerms: 1}).Check(ab); !errors.Is(err, ErrLimit) {
+		t.Errorf("Check got %v, want ErrLimit", err)
+	}
+}
@@ -0,0 +1,149 @@
+package terms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"algex/factor"
+)
+
+// ErrNonTerminating indicates that a substitution would never finish
+// because each pass reintroduces the pattern being replaced.
+var ErrNonTerminating = errors.New("non-terminating substitution")
+
+// maxPasses bounds the number of passes Substitute makes over an
+// expression. Each pass replaces one occurrence of the pattern in each
+// term, so this also bounds the power of a pattern that can be
+// replaced.
+const maxPasses = 1 << 16
+
+// SubstituteOnce replaces each occurrence of b in an expression with the
+// expression c in a single pass. Unlike Substitute, occurrences of b
+// introduced by c are left in place, so SubstituteOnce always
+// terminates. For example, substituting a -> a+b in a^2 gives
+// a^2+2*a*b+b^2.
+func SubstituteOnce(e *Exp, b []factor.Value, c *Exp) *Exp {
+	f := NewExp()
+	if e == nil {
+		return f
+	}
+	one := []factor.Value{factor.D(1, 1)}
+	for _, t := range e.terms {
+		a := append([]factor.Value{factor.R(t.coeff)}, t.fact...)
+		n, y := factor.Replace(a, b, one, 0)
+		x := NewExp(y)
+		if n != 0 {
+			p, _ := Pow(c, n)
+			x = Mul(x, p)
+		}
+		f = Add(f, x)
+	}
+	return f
+}
+
+// Rule replaces the term From with the expression To.
+type Rule struct {
+	From []factor.Value
+	To   *Exp
+}
+
+// String represents a rule as "from -> to".
+func (r Rule) String() string {
+	return fmt.Sprintf("%s -> %v", factor.Prod(r.From...), r.To)
+}
+
+// SubstituteAll applies a set of rules simultaneously in a single pass.
+// Rules take priority in the order given: every occurrence of the first
+// rule's term is removed from each term of e before the second rule
+// sees what remains, and so on. Replacements are not themselves
+// rewritten, so the rules x -> y and y -> x swap x and y.
+func SubstituteAll(e *Exp, rules ...Rule) *Exp {
+	f, _, _ := substituteAll(nil, e, rules)
+	return f
+}
+
+// substituteAll applies rules simultaneously under guard g, and reports
+// whether any of them matched.
+func substituteAll(g *guard, e *Exp, rules []Rule) (*Exp, bool, error) {
+	f := NewExp()
+	if e == nil {
+		return f, false, nil
+	}
+	one := []factor.Value{factor.D(1, 1)}
+	fired := false
+	for _, t := range e.Terms() {
+		if err := g.done(); err != nil {
+			return nil, false, err
+		}
+		x := []*Exp{nil}
+		for _, r := range rules {
+			var n int
+			if n, t = factor.Replace(t, r.From, one, 0); n == 0 {
+				continue
+			}
+			fired = true
+			p, err := pow(g, r.To, n)
+			if err != nil {
+				return nil, false, err
+			}
+			x = append(x, p)
+		}
+		x[0] = NewExp(t)
+		p, err := mul(g, x...)
+		if err != nil {
+			return nil, false, err
+		}
+		f = Add(f, p)
+	}
+	return f, fired, nil
+}
+
+// fixpointLimits bounds RewriteToFixpoint, since rules such as
+// x -> y^2 and y -> x^2 never recur but double in degree each pass.
+var fixpointLimits = Limits{MaxTerms: 1 << 16, MaxDegree: 1 << 12, MaxCoeffBits: 1 << 12}
+
+// RewriteToFixpoint applies rules, as SubstituteAll, repeatedly until
+// none of them match. It fails with an error wrapping
+// ErrNonTerminating if a rule whose replacement contains its term
+// matches, if an expression recurs, as it does for the rules x -> y
+// and y -> x, or if no fixed point is reached within a bounded number
+// of passes. It fails with an error wrapping ErrLimit if an
+// expression grows beyond 65536 terms, a power of 4096 or a
+// coefficient of 4096 bits; RewriteToFixpointCtx takes the limits of
+// its context instead.
+func RewriteToFixpoint(e *Exp, rules ...Rule) (*Exp, error) {
+	return rewrite(newGuard(WithLimits(context.Background(), fixpointLimits)), e, rules)
+}
+
+// RewriteToFixpointCtx applies rules repeatedly, like RewriteToFixpoint.
+// It fails if ctx is done or the Limits of ctx are exceeded.
+func RewriteToFixpointCtx(ctx context.Context, e *Exp, rules ...Rule) (*Exp, error) {
+	return rewrite(newGuard(ctx), e, rules)
+}
+
+// rewrite applies rules repeatedly under guard g.
+func rewrite(g *guard, e *Exp, rules []Rule) (*Exp, error) {
+	seen := map[string]bool{e.String(): true}
+	for pass := 0; pass < maxPasses; pass++ {
+		for _, r := range rules {
+			if r.To.Contains(r.From) && e.Contains(r.From) {
+				return nil, fmt.Errorf("%w: replacement of rule %v contains its term", ErrNonTerminating, r)
+			}
+		}
+		f, fired, err := substituteAll(g, e, rules)
+		if err != nil {
+			return nil, err
+		}
+		if !fired {
+			return f, nil
+		}
+		s := f.String()
+		if seen[s] {
+			return nil, fmt.Errorf("%w: %q recurs", ErrNonTerminating, s)
+		}
+		seen[s] = true
+		e = f
+	}
+	return nil, fmt.Errorf("%w: no fixed point after %d passes", ErrNonTerminating, maxPasses)
+}
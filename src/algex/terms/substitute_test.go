@@ -0,0 +1,164 @@
+package terms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "algex/factor"
+)
+
+func TestNonTerminating(t *testing.T) {
+	e := NewExp([]Value{S("a")}, []Value{S("c")})
+	b := []Value{S("a")}
+	c := NewExp([]Value{S("a")}, []Value{S("b")})
+	if _, err := SubstituteCtx(context.Background(), e, b, c); !errors.Is(err, ErrNonTerminating) {
+		t.Errorf("a -> a+b got err=%v want %v", err, ErrNonTerminating)
+	}
+	if got, err := Substitute(e, b, c); !errors.Is(err, ErrNonTerminating) {
+		t.Errorf("Substitute got=%v, %v want %v", got, err, ErrNonTerminating)
+	}
+	// A power of the pattern beyond the pass limit is reported.
+	x := NewExp([]Value{Sp("a", maxPasses+1)})
+	if got, err := Substitute(x, b, NewExp([]Value{S("b")})); !errors.Is(err, ErrNonTerminating) {
+		t.Errorf("Substitute got=%v, %v want %v", got, err, ErrNonTerminating)
+	}
+	// Without an occurrence of the pattern there is nothing to repeat.
+	f := NewExp([]Value{S("c")})
+	if got, err := SubstituteCtx(context.Background(), f, b, c); err != nil || got.String() != "c" {
+		t.Errorf("a -> a+b in c got=%v, %v want c", got, err)
+	}
+}
+
+func TestSubstituteOnce(t *testing.T) {
+	vs := []struct {
+		e, c *Exp
+		b    []Value
+		s    string
+	}{
+		{
+			e: NewExp([]Value{Sp("a", 2)}, []Value{S("c")}),
+			b: []Value{S("a")},
+			c: NewExp([]Value{S("a")}, []Value{S("b")}),
+			s: "2*a*b+a^2+b^2+c",
+		},
+		{
+			e: NewExp([]Value{D(3, 1), Sp("x", 5)}),
+			b: []Value{Sp("x", 2)},
+			c: NewExp([]Value{S("y")}),
+			s: "3*x*y^2",
+		},
+		{
+			e: NewExp([]Value{S("a"), S("b")}, []Value{S("c")}),
+			b: []Value{D(2, 1), S("a")},
+			c: NewExp([]Value{S("z")}),
+			s: "1/2*b*z+c",
+		},
+		{
+			e: NewExp([]Value{Sp("a", 2)}, []Value{Sp("b", 2)}),
+			b: []Value{S("a")},
+			c: NewExp(), // Zero.
+			s: "b^2",
+		},
+	}
+	for i, v := range vs {
+		r := SubstituteOnce(v.e, v.b, v.c)
+		if s := r.String(); s != v.s {
+			t.Errorf("[%d] %q (%q -> %q) got=%q want=%q", i, v.e, Prod(v.b...), v.c, s, v.s)
+		}
+	}
+}
+
+func TestSubstituteAll(t *testing.T) {
+	x, y := NewExp([]Value{S("x")}), NewExp([]Value{S("y")})
+	vs := []struct {
+		e     *Exp
+		rules []Rule
+		s     string
+	}{
+		{
+			e:     NewExp([]Value{Sp("x", 2), S("y")}, []Value{D(3, 1), S("x")}),
+			rules: []Rule{{From: []Value{S("x")}, To: y}, {From: []Value{S("y")}, To: x}},
+			s:     "x*y^2+3*y",
+		},
+		{
+			// The first rule consumes a*b before the second sees a.
+			e: NewExp([]Value{Sp("a", 2), S("b")}),
+			rules: []Rule{
+				{From: []Value{S("a"), S("b")}, To: NewExp([]Value{S("c")})},
+				{From: []Value{S("a")}, To: NewExp([]Value{S("d")})},
+			},
+			s: "c*d",
+		},
+		{
+			e: NewExp([]Value{Sp("a", 2), S("b")}),
+			rules: []Rule{
+				{From: []Value{S("a")}, To: NewExp([]Value{S("d")})},
+				{From: []Value{S("a"), S("b")}, To: NewExp([]Value{S("c")})},
+			},
+			s: "b*d^2",
+		},
+		{
+			e:     NewExp([]Value{S("a")}, []Value{S("x")}),
+			rules: []Rule{{From: []Value{S("x")}, To: NewExp()}},
+			s:     "a",
+		},
+	}
+	for i, v := range vs {
+		if got := SubstituteAll(v.e, v.rules...).String(); got != v.s {
+			t.Errorf("[%d] %q %v got=%q want=%q", i, v.e, v.rules, got, v.s)
+		}
+	}
+}
+
+func TestRewriteToFixpoint(t *testing.T) {
+	// st^2 -> 1-ct^2 and then ct^4 -> u leaves no match.
+	e := NewExp([]Value{Sp("st", 4)}, []Value{S("ct")})
+	rules := []Rule{
+		{From: []Value{Sp("ct", 4)}, To: NewExp([]Value{S("u")})},
+		{From: []Value{Sp("st", 2)}, To: NewExp([]Value{D(1, 1)}, []Value{D(-1, 1), Sp("ct", 2)})},
+	}
+	got, err := RewriteToFixpoint(e, rules...)
+	if err != nil {
+		t.Fatalf("rewrite failed: %v", err)
+	}
+	if want := "1+ct-2*ct^2+u"; got.String() != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+
+	x, y := NewExp([]Value{S("x")}), NewExp([]Value{S("y")})
+	swap := []Rule{{From: []Value{S("x")}, To: y}, {From: []Value{S("y")}, To: x}}
+	if _, err := RewriteToFixpoint(x, swap...); !errors.Is(err, ErrNonTerminating) {
+		t.Errorf("swap got err=%v want %v", err, ErrNonTerminating)
+	}
+	grow := []Rule{{From: []Value{S("x")}, To: NewExp([]Value{S("x")}, []Value{S("y")})}}
+	if _, err := RewriteToFixpoint(x, grow...); !errors.Is(err, ErrNonTerminating) {
+		t.Errorf("x -> x+y got err=%v want %v", err, ErrNonTerminating)
+	}
+	if got, err := RewriteToFixpoint(y, grow...); err != nil || got.String() != "y" {
+		t.Errorf("x -> x+y in y got=%v, %v want y", got, err)
+	}
+	// A rule that would never terminate is detected once another
+	// rule introduces its term.
+	z := NewExp([]Value{S("z")})
+	if _, err := RewriteToFixpoint(z, append(grow, Rule{From: []Value{S("z")}, To: x})...); !errors.Is(err, ErrNonTerminating) {
+		t.Errorf("z -> x, x -> x+y got err=%v want %v", err, ErrNonTerminating)
+	}
+	// x -> y^2 -> x^4 -> ... never recurs but grows without bound.
+	square := []Rule{
+		{From: []Value{S("x")}, To: NewExp([]Value{Sp("y", 2)})},
+		{From: []Value{S("y")}, To: NewExp([]Value{Sp("x", 2)})},
+	}
+	ctx := WithLimits(context.Background(), Limits{MaxDegree: 100})
+	if _, err := RewriteToFixpointCtx(ctx, x, square...); !errors.Is(err, ErrLimit) {
+		t.Errorf("x -> y^2, y -> x^2 got err=%v want %v", err, ErrLimit)
+	}
+	start := time.Now()
+	if _, err := RewriteToFixpoint(x, square...); !errors.Is(err, ErrLimit) {
+		t.Errorf("x -> y^2, y -> x^2 got err=%v want %v", err, ErrLimit)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("x -> y^2, y -> x^2 took %v", d)
+	}
+}
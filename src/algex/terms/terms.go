@@ -3,6 +3,7 @@ package terms
 
 import (
 	"algex/factor"
+	"fmt"
 	"math/big"
 	"sort"
 	"strings"
@@ -60,6 +61,45 @@ func (e *Exp) String() string {
 	return strings.Join(s, "")
 }
 
+// Terms returns the terms of an expression in the order String
+// displays them. Each term is a product of factors with the numerical
+// coefficient first.
+func (e *Exp) Terms() [][]factor.Value {
+	if e == nil {
+		return nil
+	}
+	var s []string
+	for x := range e.terms {
+		s = append(s, x)
+	}
+	sort.Strings(s)
+	var ts [][]factor.Value
+	for _, x := range s {
+		t := e.terms[x]
+		ts = append(ts, append([]factor.Value{factor.R(t.coeff)}, t.fact...))
+	}
+	return ts
+}
+
+// Symbols returns the sorted list of symbols used in an expression.
+func (e *Exp) Symbols() []string {
+	if e == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var syms []string
+	for _, t := range e.terms {
+		for _, f := range t.fact {
+			if !seen[f.Sym()] {
+				seen[f.Sym()] = true
+				syms = append(syms, f.Sym())
+			}
+		}
+	}
+	sort.Strings(syms)
+	return syms
+}
+
 // insert merges a coefficient, a product of factors to an expression
 // indexed by s.
 func (e *Exp) insert(n *big.Rat, fs []factor.Value, s string) {
@@ -146,8 +186,17 @@ func (e *Exp) Mod(x factor.Value) *Exp {
 
 // Mul computes the product of a series of expressions.
 func Mul(as ...*Exp) *Exp {
+	e, _ := mul(nil, as...)
+	return e
+}
+
+// mul computes the product of a series of expressions under guard g.
+func mul(g *guard, as ...*Exp) (*Exp, error) {
 	var e *Exp
 	for i, a := range as {
+		if err := g.done(); err != nil {
+			return nil, err
+		}
 		if i == 0 {
 			e = Add(a)
 			continue
@@ -160,20 +209,90 @@ func Mul(as ...*Exp) *Exp {
 				x := []factor.Value{factor.R(p.coeff), factor.R(q.coeff)}
 				n, fs, s := factor.Segment(append(x, append(p.fact, q.fact...)...)...)
 				f.insert(n, fs, s)
+				if err := g.step(f, n, fs); err != nil {
+					return nil, err
+				}
 			}
 		}
 		e = f
 	}
-	return e
+	return e, nil
 }
 
-// Substitute replaces each occurrence of b in an expression with the expression c.
-func Substitute(e *Exp, b []factor.Value, c *Exp) *Exp {
+// Pow raises an expression to an integer power. Negative powers are
+// only possible for expressions of a single non-zero term.
+func Pow(e *Exp, n int) (*Exp, error) {
+	return pow(nil, e, n)
+}
+
+// pow raises an expression to an integer power under guard g, by
+// repeated squaring.
+func pow(g *guard, e *Exp, n int) (*Exp, error) {
+	if e == nil {
+		e = NewExp()
+	}
+	if err := g.power(e, n); err != nil {
+		return nil, err
+	}
+	if n >= 0 {
+		r := NewExp([]factor.Value{factor.D(1, 1)})
+		for ; n > 0; n >>= 1 {
+			var err error
+			if n&1 == 1 {
+				if r, err = mul(g, r, e); err != nil {
+					return nil, err
+				}
+			}
+			if n > 1 {
+				if e, err = mul(g, e, e); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return r, nil
+	}
+	if len(e.terms) != 1 {
+		return nil, fmt.Errorf("unable to invert %q", e)
+	}
+	var t term
+	for _, x := range e.terms {
+		t = x
+	}
+	c := &big.Rat{}
+	v := []factor.Value{factor.R(c.Inv(t.coeff))}
+	for _, f := range t.fact {
+		v = append(v, factor.Sp(f.Sym(), -f.Pow()))
+	}
+	return pow(g, NewExp(v), -n)
+}
+
+// Substitute replaces each occurrence of b in an expression with the
+// expression c, repeating until no occurrences remain. Each pass over
+// the expression replaces one occurrence of b in each term. Substitute
+// fails with an error wrapping ErrNonTerminating if c contains b, when
+// occurrences would never be exhausted, or if occurrences remain after
+// 65536 passes.
+func Substitute(e *Exp, b []factor.Value, c *Exp) (*Exp, error) {
+	return substitute(nil, e, b, c)
+}
+
+// substitute replaces each occurrence of b in an expression with c
+// under guard g.
+func substitute(g *guard, e *Exp, b []factor.Value, c *Exp) (*Exp, error) {
+	if c != nil && c.Contains(b) && e.Contains(b) {
+		return nil, fmt.Errorf("%w: replacement %q contains %q", ErrNonTerminating, c, factor.Prod(b...))
+	}
 	s := [][]factor.Value{}
 	for _, t := range c.terms {
 		s = append(s, append([]factor.Value{factor.R(t.coeff)}, t.fact...))
 	}
-	for {
+	for pass := 0; ; pass++ {
+		if err := g.done(); err != nil {
+			return nil, err
+		}
+		if pass == maxPasses {
+			return nil, fmt.Errorf("%w: %q still present after %d passes", ErrNonTerminating, factor.Prod(b...), maxPasses)
+		}
 		again := false
 		f := &Exp{
 			terms: make(map[string]term),
@@ -184,6 +303,9 @@ func Substitute(e *Exp, b []factor.Value, c *Exp) *Exp {
 			if hit == 0 {
 				n, fs, tag := factor.Segment(y...)
 				f.insert(n, fs, tag)
+				if err := g.step(f, n, fs); err != nil {
+					return nil, err
+				}
 				// If nothing substituted, then only insert once.
 				continue
 			}
@@ -196,6 +318,9 @@ func Substitute(e *Exp, b []factor.Value, c *Exp) *Exp {
 				_, y := factor.Replace(a, b, t, 1)
 				n, fs, tag := factor.Segment(y...)
 				f.insert(n, fs, tag)
+				if err := g.step(f, n, fs); err != nil {
+					return nil, err
+				}
 			}
 		}
 		e = f
@@ -203,11 +328,77 @@ func Substitute(e *Exp, b []factor.Value, c *Exp) *Exp {
 			break
 		}
 	}
-	return e
+	return e, nil
+}
+
+// Diff differentiates an expression with respect to the symbol sym.
+func Diff(e *Exp, sym string) *Exp {
+	f := &Exp{
+		terms: make(map[string]term),
+	}
+	if e == nil {
+		return f
+	}
+	for _, t := range e.terms {
+		x := []factor.Value{factor.R(t.coeff)}
+		hit := false
+		for _, v := range t.fact {
+			if v.Sym() != sym {
+				x = append(x, v)
+				continue
+			}
+			hit = true
+			x = append(x, factor.D(int64(v.Pow()), 1), factor.Sp(sym, v.Pow()-1))
+		}
+		if !hit {
+			continue
+		}
+		n, fs, s := factor.Segment(x...)
+		if n == nil {
+			continue
+		}
+		f.insert(n, fs, s)
+	}
+	return f
+}
+
+// Collect groups the terms of an expression by the power of the
+// symbol sym that they contain. The returned map is indexed by power
+// and holds the coefficient expression of each power of sym.
+func (e *Exp) Collect(sym string) map[int]*Exp {
+	cs := make(map[int]*Exp)
+	if e == nil {
+		return cs
+	}
+	for s, t := range e.terms {
+		p := 0
+		var fs []factor.Value
+		for _, v := range t.fact {
+			if v.Sym() == sym {
+				p = v.Pow()
+				continue
+			}
+			fs = append(fs, v)
+		}
+		c, ok := cs[p]
+		if !ok {
+			c = NewExp()
+			cs[p] = c
+		}
+		if p == 0 {
+			c.insert(new(big.Rat).Set(t.coeff), t.fact, s)
+			continue
+		}
+		c.insert(new(big.Rat).Set(t.coeff), fs, factor.Prod(fs...))
+	}
+	return cs
 }
 
 // Contains investigates an expression for the presence of a term, b.
 func (e *Exp) Contains(b []factor.Value) bool {
+	if e == nil {
+		return false
+	}
 	for _, x := range e.terms {
 		a := append([]factor.Value{factor.R(x.coeff)}, x.fact...)
 		if hit, _ := factor.Replace(a, b, zero, 1); hit != 0 {
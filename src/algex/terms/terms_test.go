@@ -1,6 +1,7 @@
 package terms
 
 import (
+	"strings"
 	"testing"
 
 	. "algex/factor"
@@ -124,8 +125,10 @@ func TestSubstitute(t *testing.T) {
 		},
 	}
 	for i, v := range vs {
-		r := Substitute(v.e, v.b, v.c)
-		if s := r.String(); s != v.s {
+		r, err := Substitute(v.e, v.b, v.c)
+		if err != nil {
+			t.Errorf("[%d] %q (%q -> %q) failed: %v", i, v.e, Prod(v.b...), v.c, err)
+		} else if s := r.String(); s != v.s {
 			t.Errorf("[%d] %q (%q -> %q) got=%q want=%q", i, v.e, Prod(v.b...), v.c, s, v.s)
 		}
 	}
@@ -164,3 +167,82 @@ func TestContains(t *testing.T) {
 		}
 	}
 }
+
+func TestPow(t *testing.T) {
+	vs := []struct {
+		e *Exp
+		n int
+		s string
+	}{
+		{e: NewExp([]Value{S("a")}, []Value{S("b")}), n: 0, s: "1"},
+		{e: NewExp([]Value{S("a")}, []Value{S("b")}), n: 2, s: "2*a*b+a^2+b^2"},
+		{e: NewExp([]Value{D(2, 3), S("a"), Sp("b", -2)}), n: -2, s: "9/4*a^-2*b^4"},
+		{e: NewExp([]Value{S("a")}, []Value{S("b")}), n: 5, s: "5*a*b^4+10*a^2*b^3+10*a^3*b^2+5*a^4*b+a^5+b^5"},
+		{e: NewExp([]Value{S("a")}), n: 1 << 22, s: "a^4194304"},
+		{e: NewExp(), n: 0, s: "1"},
+		{e: NewExp([]Value{S("a")}, []Value{S("b")}), n: -1},
+		{e: NewExp(), n: -1},
+	}
+	for i, v := range vs {
+		p, err := Pow(v.e, v.n)
+		if v.s == "" {
+			if err == nil {
+				t.Errorf("[%d] (%q)^%d: got=%q, want error", i, v.e, v.n, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] (%q)^%d failed: %v", i, v.e, v.n, err)
+		} else if s := p.String(); s != v.s {
+			t.Errorf("[%d] (%q)^%d got=%q want=%q", i, v.e, v.n, s, v.s)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	e := NewExp([]Value{D(-2, 1), S("b")}, []Value{S("a"), S("c")}, []Value{D(5, 1)})
+	var got []string
+	for _, x := range e.Terms() {
+		got = append(got, Prod(x...))
+	}
+	if s := strings.Join(got, " "); s != "5 a*c -2*b" {
+		t.Errorf("got=%q", s)
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	e := NewExp([]Value{D(-2, 1), S("b")}, []Value{S("a"), Sp("c", -1)}, []Value{D(5, 1), S("b")})
+	if got, want := strings.Join(e.Symbols(), ","), "a,b,c"; got != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	e := NewExp([]Value{D(1, 3), Sp("x", 3), S("y")}, []Value{D(2, 1), Sp("x", -1)}, []Value{S("y")})
+	vs := []struct {
+		sym, s string
+	}{
+		{sym: "x", s: "-2*x^-2+x^2*y"},
+		{sym: "y", s: "1+1/3*x^3"},
+		{sym: "z", s: "0"},
+	}
+	for i, v := range vs {
+		if got := Diff(e, v.sym).String(); got != v.s {
+			t.Errorf("[%d] d(%q)/d%s got=%q want=%q", i, e, v.sym, got, v.s)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	e := NewExp([]Value{D(3, 1), Sp("x", 2), S("a")}, []Value{Sp("x", 2), S("b")}, []Value{S("x")}, []Value{S("c")}, []Value{D(-1, 1), Sp("x", -1)})
+	cs := e.Collect("x")
+	want := map[int]string{2: "3*a+b", 1: "1", 0: "c", -1: "-1"}
+	if len(cs) != len(want) {
+		t.Errorf("got %d powers, want %d", len(cs), len(want))
+	}
+	for p, s := range want {
+		if got := cs[p].String(); got != s {
+			t.Errorf("x^%d: got=%q want=%q", p, got, s)
+		}
+	}
+}